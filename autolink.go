@@ -0,0 +1,93 @@
+package markout
+
+import "strings"
+
+// autolink_match is a URL or an e-mail address detected in plain text.
+type autolink_match struct {
+	start, end int    // byte range of the text
+	url        string // link target
+}
+
+// autolink_prefixes are recognized at word boundaries, the bare `www.`
+// domains are linked with the http scheme, the same way as in GitHub
+// flavored markdown.
+var autolink_prefixes = []string{"https://", "http://", "mailto:", "www."}
+
+// find_autolinks detects URLs and e-mail addresses in s.
+func find_autolinks(s string) []autolink_match {
+	var r []autolink_match
+	last := 0 // end of the last match
+	for i := 0; i < len(s); i++ {
+		if i > 0 && is_alnum(s[i-1]) {
+			if s[i] == '@' {
+				if m, ok := match_email(s, last, i); ok {
+					r = append(r, m)
+					last, i = m.end, m.end-1
+				}
+			}
+			continue
+		}
+		for _, prefix := range autolink_prefixes {
+			if len(s)-i <= len(prefix) || !strings.EqualFold(s[i:i+len(prefix)], prefix) {
+				continue
+			}
+			end := url_end(s, i)
+			if end-i <= len(prefix) || (prefix == "www." && strings.IndexByte(s[i+len(prefix):end], '.') < 0) {
+				continue
+			}
+			m := autolink_match{start: i, end: end, url: s[i:end]}
+			if prefix == "www." {
+				m.url = "http://" + m.url
+			}
+			r = append(r, m)
+			last, i = end, end-1
+			break
+		}
+	}
+	return r
+}
+
+// url_end finds the end of the URL that starts at i; trailing punctuation
+// and unbalanced closing parentheses are not included.
+func url_end(s string, i int) int {
+	end := i
+	for end < len(s) && s[end] > ' ' && s[end] != '<' && s[end] != '>' && s[end] != '"' {
+		end++
+	}
+	for end > i {
+		c := s[end-1]
+		if strings.IndexByte(".,:;!?'*_~", c) >= 0 {
+			end--
+		} else if c == ')' && strings.Count(s[i:end], ")") > strings.Count(s[i:end], "(") {
+			end--
+		} else {
+			break
+		}
+	}
+	return end
+}
+
+// match_email matches an e-mail address around the '@' character at pos,
+// the address can not start before the lower bound.
+func match_email(s string, lower, pos int) (autolink_match, bool) {
+	start := pos
+	for start > lower && (is_alnum(s[start-1]) || strings.IndexByte("._%+-", s[start-1]) >= 0) {
+		start--
+	}
+	end := pos + 1
+	for end < len(s) && (is_alnum(s[end]) || s[end] == '.' || s[end] == '-') {
+		end++
+	}
+	for end > pos+1 && (s[end-1] == '.' || s[end-1] == '-') {
+		end--
+	}
+	domain := s[pos+1 : end]
+	if start == pos || strings.IndexByte(domain, '.') <= 0 {
+		return autolink_match{}, false
+	}
+	return autolink_match{start: start, end: end, url: "mailto:" + s[start:end]}, true
+}
+
+func is_alnum(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
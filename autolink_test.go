@@ -0,0 +1,80 @@
+package markout
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func Test_find_autolinks(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []string
+	}{
+		{"no links here", nil},
+		{"see https://example.com/a_(b).", []string{"https://example.com/a_(b)"}},
+		{"(http://example.com/x)", []string{"http://example.com/x"}},
+		{"visit www.example.com, or www.", []string{"http://www.example.com"}},
+		{"mail bob.smith+go@example.co.uk.", []string{"mailto:bob.smith+go@example.co.uk"}},
+		{"mailto:alice@example.com and @handle or a@b", []string{"mailto:alice@example.com"}},
+		{"xhttps://example.com", nil},
+		{"HTTPS://EXAMPLE.COM!", []string{"HTTPS://EXAMPLE.COM"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, m := range find_autolinks(tt.s) {
+			got = append(got, m.url)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("find_autolinks(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func Test_autolink_printf(t *testing.T) {
+	tests := []struct {
+		format string
+		args   []any
+		want   string
+	}{
+		{"Docs at https://example.com/%s today", []any{"page"}, "Docs at https://example.com/page today"},
+		{"Mail %s@example.com", []any{"bob"}, "Mail bob@example.com"},
+		{"See https://example.com/issues (%d open)", []any{3}, `See <a href="https://example.com/issues">https://example.com/issues</a> (3 open)`},
+		{"Mail %s", []any{"bob@example.com"}, `Mail <a href="mailto:bob@example.com">bob@example.com</a>`},
+	}
+	for _, tt := range tests {
+		b := bytes.Buffer{}
+		ii := &html_inlines{}
+		ii.autolink = true
+		to_buffer(&b, ii, nil, func(p Printer) {
+			p.Printf(tt.format, tt.args...)
+		})
+		if got := b.String(); got != tt.want {
+			t.Errorf("Printf(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+}
+
+func ExampleHTMLOptions_autolink() {
+	write_each(func(w Writer) {
+		w.Para("Report issues at https://example.com/issues or to bugs@example.com.")
+		w.Paraf("Docs: %s, sources: www.example.com/src", Link("https://example.com/docs", "https://example.com/docs"))
+		w.Para(Code("https://example.com/not-a-link"))
+	}, HTMLOptions{Autolink: true}, MDOptions{Autolink: true})
+	// Output:
+	// <html>
+	// <body>
+	// <p>Report issues at <a href="https://example.com/issues">https://example.com/issues</a> or to <a href="mailto:bugs@example.com">bugs@example.com</a>.</p>
+	//
+	// <p>Docs: <a href="https://example.com/docs">https://example.com/docs</a>, sources: <a href="http://www.example.com/src">www.example.com/src</a></p>
+	//
+	// <p><code>https://example.com/not-a-link</code></p>
+	//
+	// </body>
+	// </html>
+	// Report issues at [https://example\.com/issues](https://example.com/issues) or to [bugs@example\.com](mailto:bugs@example.com)\.
+	//
+	// Docs: [https://example\.com/docs](https://example.com/docs), sources: [www\.example\.com/src](http://www.example.com/src)
+	//
+	// `https://example.com/not-a-link`
+}
@@ -1,7 +1,10 @@
 package markout
 
 import (
+	"bytes"
 	"io"
+
+	"golang.org/x/exp/slices"
 )
 
 type base_blocks struct {
@@ -11,7 +14,10 @@ type base_blocks struct {
 	sect_levels       []int // section level counters
 	list_levels       []int // list level counters (-1 for unordered levels)
 	list_level_broads []bool
+	list_level_styles []NumberStyle
 	table             table_grid
+	table_opts        table_opts
+	table_stream      table_stream
 }
 
 func (bb *base_blocks) current_mode() bmode {
@@ -42,8 +48,31 @@ func (bb *base_blocks) close() {
 	bb.table = bb.table[:0]
 	bb.sect_levels = bb.sect_levels[:0]
 	bb.list_levels = bb.list_levels[:0]
+	bb.list_level_broads = bb.list_level_broads[:0]
+	bb.list_level_styles = bb.list_level_styles[:0]
 	bb.do_nextline()
 	bb.eols = 0
+	if d, ok := bb.out.(*deferred_output); ok {
+		d.flush()
+		bb.out = d.out
+	}
+}
+
+// defer_output starts buffering the output until the writer is closed.
+func (bb *base_blocks) defer_output() *deferred_output {
+	d, ok := bb.out.(*deferred_output)
+	if !ok {
+		d = &deferred_output{out: bb.out}
+		bb.out = d
+	}
+	return d
+}
+
+func (bb *base_blocks) placeholder_block(resolve func() RawContent) {
+	if bb.enabled() {
+		bb.putblock(bb.defer_output().placeholder(resolve))
+	}
+	bb.want_emptyln()
 }
 
 func (bb *base_blocks) want_nextln() {
@@ -86,20 +115,60 @@ func (bb *base_blocks) pop_disabled() {
 	}
 }
 
-func (bb *base_blocks) begin_table(columns ...RawContent) {
-	hh := []RawContent{}
+func (bb *base_blocks) begin_table(opts table_opts, columns ...table_cell) {
+	bb.table_opts = opts
+	bb.table_stream = table_stream{}
+	hh := []table_cell{}
 	hh = append(hh, columns...)
 	bb.table = append(bb.table, hh)
 }
 
-func (bb *base_blocks) table_row(cells ...RawContent) {
+func (bb *base_blocks) table_row(cells ...table_cell) {
 	if bb.enabled() {
-		cc := []RawContent{}
+		cc := []table_cell{}
 		cc = append(cc, cells...)
 		bb.table = append(bb.table, cc)
 	}
 }
 
+// defer_table postpones writing the table until the placeholders within its
+// cells are resolved, column widths can only be measured on the resolved
+// content. It returns false if the table does not contain placeholders, or
+// when some of its rows are already written.
+func (bb *base_blocks) defer_table(flush func(final bool)) bool {
+	d, ok := bb.out.(*deferred_output)
+	if !ok || bb.table_stream.written > 0 || !bb.table.has_placeholders(d, bb.table_opts.caption) {
+		return false
+	}
+	grid, opts := slices.Clone(bb.table), bb.table_opts
+	bb.placeholder_block(func() RawContent {
+		for _, row := range grid {
+			for i := range row {
+				for k := range row[i].blocks {
+					row[i].blocks[k].content = d.resolve(row[i].blocks[k].content)
+				}
+			}
+		}
+		opts.caption = d.resolve(opts.caption)
+		b := bytes.Buffer{}
+		out, eols, table, table_opts, stream := bb.out, bb.eols, bb.table, bb.table_opts, bb.table_stream
+		bb.out, bb.eols, bb.table, bb.table_opts, bb.table_stream = &b, 0, grid, opts, table_stream{}
+		flush(true)
+		bb.out, bb.eols, bb.table, bb.table_opts, bb.table_stream = out, eols, table, table_opts, stream
+		return b.Bytes()
+	})
+	return true
+}
+
+// table_flushed is called after pending rows of a streaming table are
+// written out, only the header row is kept in the table grid.
+func (bb *base_blocks) table_flushed() {
+	if bb.table_opts.streaming && len(bb.table) > 1 {
+		bb.table = bb.table[:1]
+		bb.table_stream.added = 1
+	}
+}
+
 func (bb *base_blocks) do_nextline() {
 	n := bb.eols
 	if n > 2 {
@@ -125,6 +194,11 @@ func (bb *base_blocks) putblock_ex(indent_level int, prefix string, s RawContent
 	}
 }
 
+func (bb *base_blocks) raw_block(s RawContent) {
+	bb.putblock(s)
+	bb.want_emptyln()
+}
+
 func (bb *base_blocks) sect_level_in() {
 	bb.sect_levels = append(bb.sect_levels, 0)
 }
@@ -138,15 +212,23 @@ func (bb *base_blocks) sect_counters() []int {
 	return bb.sect_levels
 }
 
-func (bb *base_blocks) list_level_in(initial int, broad bool) {
+func (bb *base_blocks) list_level_in(initial int, broad bool, style NumberStyle) {
 	bb.list_levels = append(bb.list_levels, initial)
 	bb.list_level_broads = append(bb.list_level_broads, broad)
+	bb.list_level_styles = append(bb.list_level_styles, style)
 }
 
 func (bb *base_blocks) list_level_out() {
 	n := len(bb.list_levels)
 	bb.list_levels = bb.list_levels[:n-1]
 	bb.list_level_broads = bb.list_level_broads[:n-1]
+	bb.list_level_styles = bb.list_level_styles[:n-1]
+}
+
+// list_marker formats the marker of an ordered list item at the given level:
+// `1. `, `a. `, `iv. `
+func (bb *base_blocks) list_marker(level int, counter int) string {
+	return bb.list_level_styles[level-1].Format(counter) + ". "
 }
 
 func (bb *base_blocks) list_level_info() (counters []int, broads []bool) {
@@ -155,6 +237,14 @@ func (bb *base_blocks) list_level_info() (counters []int, broads []bool) {
 	return
 }
 
+// indent_lines inserts ind after each line break within s.
+func indent_lines(s RawContent, ind string) RawContent {
+	if bytes.IndexByte(s, '\n') < 0 {
+		return s
+	}
+	return bytes.ReplaceAll(s, []byte{'\n'}, []byte("\n"+ind))
+}
+
 func pick[T any](use_second bool, first, second T) T {
 	if use_second {
 		return second
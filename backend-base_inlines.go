@@ -1,13 +1,63 @@
 package markout
 
 import (
+	"bytes"
 	"errors"
 	"strings"
 )
 
 type base_inlines struct {
-	quote_specs [4]string // quotation marks [<single_open>, <single_close>, <double_open>, <double_close>]
-	style_stack []Style
+	quote_specs  [4]string // quotation marks [<single_open>, <single_close>, <double_open>, <double_close>]
+	style_stack  []Style
+	doc          *document
+	autolink     bool // detect URLs and e-mail addresses in plain strings
+	autolink_off int  // nesting depth of the content where detection is suppressed
+	emoji_mode   Emoji
+	smart        bool          // smart typography in plain strings
+	smart_off    int           // nesting depth of the content where smart typography is suppressed
+	text_buf     *bytes.Buffer // the buffer where the text continues after markup, see mark_text
+	text_at      int           // the position in text_buf where the text continues
+	text_prev    rune          // the last text character before the markup
+}
+
+func (ii *base_inlines) emoji() Emoji {
+	return ii.emoji_mode
+}
+
+// autolink_active reports whether URLs should be detected in plain strings.
+func (ii *base_inlines) autolink_active() bool {
+	return ii.autolink && ii.autolink_off == 0
+}
+
+// suppress_autolink disables URL detection in the nested content, such as
+// link captions, the calls must be paired.
+func (ii *base_inlines) suppress_autolink(suppress bool) {
+	if suppress {
+		ii.autolink_off++
+	} else {
+		ii.autolink_off--
+	}
+}
+
+// suppress_smart disables smart typography in the nested content, such as
+// URLs, the calls must be paired.
+func (ii *base_inlines) suppress_smart(suppress bool) {
+	if suppress {
+		ii.smart_off++
+	} else {
+		ii.smart_off--
+	}
+}
+
+func (ii *base_inlines) end_content(b *bytes.Buffer, final bool) {
+}
+
+func (ii *base_inlines) document() *document {
+	return ii.doc
+}
+
+func (ii *base_inlines) set_document(doc *document) {
+	ii.doc = doc
 }
 
 func (ii *base_inlines) setup_quotation_marks(quote_fmt string) {
@@ -1,7 +1,9 @@
 package markout
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"sort"
 	"strconv"
 	"strings"
@@ -10,6 +12,8 @@ import (
 type html_blocks struct {
 	base_blocks
 	list_title_class string
+	math_renderer    MathRenderer
+	diagram_renderer DiagramRenderer
 }
 
 func (bb *html_blocks) para(s RawContent) {
@@ -17,10 +21,55 @@ func (bb *html_blocks) para(s RawContent) {
 	bb.want_emptyln()
 }
 
-func (bb *html_blocks) heading(counters []int, s RawContent, aa *Attrs) {
-	level := len(counters)
-	tagname := "h" + strconv.Itoa(level)
-	t := fmt.Sprintf("<%s", tagname)
+func (bb *html_blocks) heading(h *heading_info) {
+	tagname := "h" + strconv.Itoa(h.level())
+	aa := h.attrs
+	if h.anchor {
+		aa = &Attrs{Identifier: h.id}
+		if h.attrs != nil {
+			aa.Classes = h.attrs.Classes
+			aa.KeyVals = h.attrs.KeyVals
+		}
+	}
+	t := "<" + tagname + html_attrs(aa) + ">"
+	bb.putblock_ex(0, t, h.numbered(h.caption), "</"+tagname+">")
+	bb.want_emptyln()
+}
+
+// toc writes a navigation block with nested lists of links to section
+// headings.
+func (bb *html_blocks) toc(entries []*heading_info, max_level int) RawContent {
+	b := bytes.Buffer{}
+	b.WriteString("<nav class=\"toc\">")
+	depth := 0
+	for _, h := range entries {
+		level := h.level()
+		if max_level > 0 && level > max_level {
+			continue
+		}
+		for ; depth > level; depth-- {
+			b.WriteString("</li>\n</ul>")
+		}
+		if depth == level {
+			b.WriteString("</li>")
+		}
+		for ; depth < level; depth++ {
+			b.WriteString("\n<ul>")
+		}
+		fmt.Fprintf(&b, "\n<li><a href=\"#%s\">", h.id)
+		b.Write(h.numbered(h.linkable))
+		b.WriteString("</a>")
+	}
+	for ; depth > 0; depth-- {
+		b.WriteString("</li>\n</ul>")
+	}
+	b.WriteString("\n</nav>")
+	return b.Bytes()
+}
+
+// html_attrs formats id, class, and key-value attributes for an opening tag.
+func html_attrs(aa *Attrs) string {
+	t := ""
 	if aa != nil {
 		if aa.Identifier != "" {
 			t += fmt.Sprintf(" id=\"%s\"", aa.Identifier)
@@ -35,13 +84,11 @@ func (bb *html_blocks) heading(counters []int, s RawContent, aa *Attrs) {
 			}
 			sort.Strings(kk)
 			for _, k := range kk {
-				t += fmt.Sprintf(" %s=\"%s\"", k, aa.KeyVals[k])
+				t += fmt.Sprintf(" %s=\"%s\"", k, html_attr_escape(aa.KeyVals[k]))
 			}
 		}
 	}
-	t += ">"
-	bb.putblock_ex(0, t, s, "</"+tagname+">")
-	bb.want_emptyln()
+	return t
 }
 
 func (bb *html_blocks) list_title(s RawContent) {
@@ -56,7 +103,7 @@ func (bb *html_blocks) list_title(s RawContent) {
 func (bb *html_blocks) list_level_start(counters []int, _ bool) {
 	if bb.enabled() {
 		n := len(counters) - 1
-		bb.putblock_ex(n, pick(counters[n] >= 0, "<ul>", "<ol>"), []byte{}, "")
+		bb.putblock_ex(n, pick(counters[n] >= 0, "<ul>", html_ol(counters[n]+1, bb.list_level_styles[n])), []byte{}, "")
 	}
 	bb.want_nextln()
 }
@@ -73,12 +120,12 @@ func (bb *html_blocks) list_level_done(counters []int, _ bool) {
 	}
 }
 
-func (bb *html_blocks) list_item(counters []int, broad bool, s ...RawContent) {
+func (bb *html_blocks) list_item(counters []int, broad bool, task list_task, s ...RawContent) {
 	if bb.enabled() {
 		n := len(counters) - 1
 		switch len(s) {
 		case 0:
-			bb.putblock_ex(n+1, "<li>", nil, "</li>")
+			bb.putblock_ex(n+1, "<li>"+html_checkbox(task), nil, "</li>")
 		default:
 			for i, b := range s {
 				before := ""
@@ -89,7 +136,7 @@ func (bb *html_blocks) list_item(counters []int, broad bool, s ...RawContent) {
 				}
 				lvl := n + 1
 				if i == 0 {
-					before = "<li>" + before
+					before = "<li>" + before + html_checkbox(task)
 				} else {
 					lvl++
 				}
@@ -104,31 +151,161 @@ func (bb *html_blocks) list_item(counters []int, broad bool, s ...RawContent) {
 	bb.want_nextln()
 }
 
-func (bb *html_blocks) end_table() {
-	if len(bb.table) > 1 {
+// table_row writes rows as they arrive, the header is written along with the
+// first row.
+func (bb *html_blocks) table_row(cells ...table_cell) {
+	if !bb.enabled() {
+		return
+	}
+	st := &bb.table_stream
+	if st.written == 0 {
 		bb.do_nextline()
-		bb.out.Write([]byte("<table>\n<thead><tr>"))
-		for _, c := range bb.table[0] {
-			bb.out.Write([]byte("<th>"))
-			bb.out.Write(c)
-			bb.out.Write([]byte("</th>"))
-		}
-		bb.out.Write([]byte("</tr></thead>\n<tbody>"))
-		for _, row := range bb.table[1:] {
-			bb.out.Write([]byte("\n<tr>"))
-			for _, c := range row {
-				bb.out.Write([]byte("<td>"))
-				bb.out.Write(c)
-				bb.out.Write([]byte("</td>"))
-			}
-			bb.out.Write([]byte("</tr>"))
-		}
-		bb.out.Write([]byte("\n</tbody>\n</table>"))
-		bb.table = bb.table[:0]
+		html_table_begin(bb.out, st.layout.place(bb.table[0]), &bb.table_opts, html_cell_content)
+		st.written++
 	}
+	html_table_row(bb.out, st.layout.place(cells), &bb.table_opts, html_cell_content)
+	st.written++
+}
+
+func (bb *html_blocks) end_table() {
+	if bb.table_stream.written > 0 {
+		html_table_end(bb.out)
+	}
+	bb.table = bb.table[:0]
 	bb.want_emptyln()
 }
 
+// cell_content_writer writes the content of table cells and captions
+// within html table markup.
+type cell_content_writer func(w io.Writer, c *table_cell)
+
+// html_table writes table markup, it is shared with the backends that fall
+// back to html for the layouts they can not handle natively.
+func html_table(w io.Writer, t table_grid, opts *table_opts, content cell_content_writer) {
+	rows := t.layout()
+	html_table_begin(w, rows[0], opts, content)
+	for _, row := range rows[1:] {
+		html_table_row(w, row, opts, content)
+	}
+	html_table_end(w)
+}
+
+func html_table_begin(w io.Writer, header []table_slot, opts *table_opts, content cell_content_writer) {
+	w.Write([]byte("<table" + html_attrs(opts.attrs) + ">\n"))
+	if len(opts.caption) > 0 {
+		w.Write([]byte("<caption>"))
+		content(w, &table_cell{blocks: []cell_block{{content: opts.caption}}})
+		w.Write([]byte("</caption>\n"))
+	}
+	w.Write([]byte("<thead><tr>"))
+	html_table_cells(w, "th", header, opts, content)
+	w.Write([]byte("</tr></thead>\n<tbody>"))
+}
+
+func html_table_row(w io.Writer, row []table_slot, opts *table_opts, content cell_content_writer) {
+	w.Write([]byte("\n<tr>"))
+	html_table_cells(w, "td", row, opts, content)
+	w.Write([]byte("</tr>"))
+}
+
+func html_table_end(w io.Writer) {
+	w.Write([]byte("\n</tbody>\n</table>"))
+}
+
+func html_table_cells(w io.Writer, tagname string, slots []table_slot, opts *table_opts, content cell_content_writer) {
+	for _, s := range slots {
+		if s.covered {
+			continue
+		}
+		t := "<" + tagname + html_align(opts.align(s.col))
+		if s.cell.cols > 1 {
+			t += fmt.Sprintf(" colspan=\"%d\"", s.cell.cols)
+		}
+		if s.cell.rows > 1 {
+			t += fmt.Sprintf(" rowspan=\"%d\"", s.cell.rows)
+		}
+		w.Write([]byte(t + ">"))
+		content(w, s.cell)
+		w.Write([]byte("</" + tagname + ">"))
+	}
+}
+
+// html_cell_content writes table cell blocks, simple cells are written as-is,
+// multiple paragraphs and list items are nested inside the cell.
+func html_cell_content(w io.Writer, c *table_cell) {
+	if c.simple() {
+		w.Write(c.blocks[0].content)
+		return
+	}
+	open := []bool{} // open lists, true for ordered
+	close_list := func() {
+		n := len(open) - 1
+		w.Write([]byte(pick(open[n], "</li></ul>", "</li></ol>")))
+		open = open[:n]
+	}
+	for _, b := range c.blocks {
+		for len(open) > b.level {
+			close_list()
+		}
+		if !b.is_item() {
+			w.Write([]byte("<p>"))
+			w.Write(b.content)
+			w.Write([]byte("</p>"))
+			continue
+		}
+		if len(open) == b.level {
+			w.Write([]byte("</li><li>"))
+		}
+		for len(open) < b.level {
+			open = append(open, b.counter >= 0)
+			w.Write([]byte(pick(b.counter >= 0, "<ul><li>", html_ol(b.counter, b.style)+"<li>")))
+		}
+		w.Write([]byte(html_checkbox(b.task)))
+		w.Write(b.content)
+	}
+	for len(open) > 0 {
+		close_list()
+	}
+}
+
+// html_ol formats the opening tag of ordered lists.
+func html_ol(start int, style NumberStyle) string {
+	t := "<ol"
+	if start != 1 {
+		t += fmt.Sprintf(" start=\"%d\"", start)
+	}
+	if s := style.html_type(); s != "" {
+		t += fmt.Sprintf(" type=\"%s\"", s)
+	}
+	return t + ">"
+}
+
+// html_checkbox formats a disabled checkbox for task list items.
+func html_checkbox(t list_task) string {
+	switch t {
+	case task_open:
+		return `<input type="checkbox" disabled> `
+	case task_done:
+		return `<input type="checkbox" checked disabled> `
+	default:
+		return ""
+	}
+}
+
+// html_align formats the style attribute for aligned table cells.
+func html_align(a Align) string {
+	switch a {
+	case AlignLeft:
+		return " style=\"text-align:left\""
+	case AlignCenter:
+		return " style=\"text-align:center\""
+	case AlignRight:
+		return " style=\"text-align:right\""
+	default:
+		return ""
+	}
+}
+
 func (bb *html_blocks) codeblock(lang string, s RawContent) {
 	bb.want_emptyln()
 	bb.do_nextline()
@@ -143,3 +320,53 @@ func (bb *html_blocks) codeblock(lang string, s RawContent) {
 	bb.out.Write([]byte("\n</pre>"))
 	bb.want_emptyln()
 }
+
+const html_page_break = `<div style="page-break-after: always"></div>`
+
+func (bb *html_blocks) thematic_break() {
+	bb.putblock(RawContent("<hr>"))
+	bb.want_emptyln()
+}
+
+func (bb *html_blocks) page_break() {
+	bb.putblock(RawContent(html_page_break))
+	bb.want_emptyln()
+}
+
+func (bb *html_blocks) math_block(tex string) (err error) {
+	b := bytes.Buffer{}
+	if bb.math_renderer != nil {
+		var s RawContent
+		if s, err = bb.math_renderer.RenderMath(tex, true); err == nil {
+			b.Write(s)
+		}
+	}
+	if bb.math_renderer == nil || err != nil {
+		b.WriteString(`<div class="math display">\[`)
+		html_scramble(&b, source_lines(tex))
+		b.WriteString(`\]</div>`)
+	}
+	bb.putblock(b.Bytes())
+	bb.want_emptyln()
+	return err
+}
+
+// diagram writes the content produced by the diagram renderer, or the source
+// within a <pre> element classified with the diagram kind, as expected by
+// client-side renderers such as Mermaid.
+func (bb *html_blocks) diagram(kind, source string) (err error) {
+	var s RawContent
+	if bb.diagram_renderer != nil {
+		s, err = bb.diagram_renderer.RenderDiagram(kind, source)
+	}
+	if s == nil || err != nil {
+		b := bytes.Buffer{}
+		b.WriteString("<pre class=\"" + html_attr_escape(kind) + "\">\n")
+		html_scramble(&b, source_lines(source))
+		b.WriteString("\n</pre>")
+		s = b.Bytes()
+	}
+	bb.putblock(s)
+	bb.want_emptyln()
+	return err
+}
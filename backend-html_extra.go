@@ -3,6 +3,7 @@ package markout
 import (
 	"bytes"
 	"fmt"
+	"strings"
 )
 
 // things related exclusively to HTML file structure
@@ -18,8 +19,8 @@ func (bb *html_blocks) end_html() {
 	bb.want_nextln()
 }
 
-func (bb *html_blocks) head(title, style RawContent) {
-	if len(title) == 0 && len(style) == 0 {
+func (bb *html_blocks) head(title, style RawContent, meta Metadata, scripts []string) {
+	if len(title) == 0 && len(style) == 0 && len(meta) == 0 && len(scripts) == 0 {
 		return
 	}
 	bb.putblock(RawContent("<head>"))
@@ -28,10 +29,25 @@ func (bb *html_blocks) head(title, style RawContent) {
 		bb.putblock_ex(1, "<title>", title, "</title>")
 		bb.want_nextln()
 	}
+	for _, k := range meta.keys() {
+		if k == "title" {
+			continue
+		}
+		name := k
+		if k == "tags" {
+			name = "keywords"
+		}
+		bb.putblock_ex(1, "<meta name=\""+html_attr_escape(name)+"\" content=\"", RawContent(html_attr_escape(meta_text(meta[k]))), "\">")
+		bb.want_nextln()
+	}
 	if len(style) > 0 {
 		bb.putblock_ex(1, "<style>", style, "</style>")
 		bb.want_nextln()
 	}
+	for _, src := range scripts {
+		bb.putblock_ex(1, "<script src=\""+html_attr_escape(src)+"\">", nil, "</script>")
+		bb.want_nextln()
+	}
 	bb.putblock(RawContent("</head>"))
 	bb.want_nextln()
 }
@@ -46,8 +62,8 @@ func (bb *html_blocks) end_body() {
 	bb.want_nextln()
 }
 
-func (ii *html_inlines) simple_link(b *bytes.Buffer, caption RawContent, url RawContent) {
-	fmt.Fprintf(b, "<a href=\"%s\">", url)
+func (ii *html_inlines) simple_link(b *bytes.Buffer, caption RawContent, url RawContent, la *LinkAttrs) {
+	html_link_open(b, url, la, &ii.external_links)
 	if len(caption) == 0 {
 		b.Write(url)
 	} else {
@@ -55,3 +71,51 @@ func (ii *html_inlines) simple_link(b *bytes.Buffer, caption RawContent, url Raw
 	}
 	b.WriteString("</a>")
 }
+
+// html_link_open writes the opening anchor tag. The defaults for external
+// links are applied to the URLs with a scheme when la does not specify the
+// attributes, ext may be nil.
+func html_link_open(b *bytes.Buffer, url RawContent, la *LinkAttrs, ext *LinkAttrs) {
+	fmt.Fprintf(b, "<a href=\"%s\"", url)
+	var a LinkAttrs
+	if la != nil {
+		a = *la
+	}
+	if ext != nil && is_external_url(string(url)) {
+		if a.Rel == "" {
+			a.Rel = ext.Rel
+		}
+		if a.Target == "" {
+			a.Target = ext.Target
+		}
+	}
+	if a.Title != "" {
+		b.WriteString(" title=\"" + html_attr_escape(a.Title) + "\"")
+	}
+	if a.Rel != "" {
+		b.WriteString(" rel=\"" + html_attr_escape(a.Rel) + "\"")
+	}
+	if a.Target != "" {
+		b.WriteString(" target=\"" + html_attr_escape(a.Target) + "\"")
+	}
+	b.WriteByte('>')
+}
+
+// is_external_url reports whether the URL points to another site:
+// `https://host/path` or `//host/path`.
+func is_external_url(url string) bool {
+	return strings.HasPrefix(url, "//") || strings.Contains(url, "://")
+}
+
+func (ii *html_inlines) anchor_link(b *bytes.Buffer, caption RawContent, id string) {
+	fmt.Fprintf(b, "<a href=\"#%s\">", id)
+	b.Write(caption)
+	b.WriteString("</a>")
+}
+
+// html_attr_escape escapes s for use within a double-quoted attribute value.
+func html_attr_escape(s string) string {
+	return html_attr_replacer.Replace(s)
+}
+
+var html_attr_replacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\"", "&quot;")
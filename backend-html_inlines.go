@@ -2,12 +2,17 @@ package markout
 
 import (
 	"bytes"
-	"fmt"
 )
 
 type html_inlines struct {
 	base_inlines
-	in_link bool
+	in_link        bool
+	math_renderer  MathRenderer
+	external_links LinkAttrs // defaults for the links to other sites
+}
+
+func (ii *html_inlines) format() string {
+	return FormatHTML
 }
 
 func (ii *html_inlines) current_mode() imode {
@@ -42,7 +47,7 @@ func (ii *html_inlines) put_raw(b *bytes.Buffer, s RawContent) {
 	b.Write(s)
 }
 func (ii *html_inlines) put_str(b *bytes.Buffer, s string) {
-	html_scramble(b, s)
+	html_scramble(b, ii.smarten(b, s))
 }
 func (ii *html_inlines) code_raw(b *bytes.Buffer, s RawContent) {
 	b.WriteString("<code>")
@@ -57,6 +62,17 @@ func (ii *html_inlines) code_str(b *bytes.Buffer, s string) {
 func (ii *html_inlines) codeblock_line(b *bytes.Buffer, s string) {
 	html_scramble(b, s)
 }
+func (ii *html_inlines) line_break(b *bytes.Buffer) {
+	b.WriteString("<br>")
+}
+func (ii *html_inlines) math(b *bytes.Buffer, tex string) {
+	if render_math(b, ii.math_renderer, ii.doc, tex, false) {
+		return
+	}
+	b.WriteString(`<span class="math inline">\(`)
+	html_scramble(b, tex)
+	b.WriteString(`\)</span>`)
+}
 func (ii *html_inlines) begin_styled(b *bytes.Buffer, sty Style) {
 	ii.start_styled(sty)
 	switch sty {
@@ -64,10 +80,9 @@ func (ii *html_inlines) begin_styled(b *bytes.Buffer, sty Style) {
 		b.WriteString(ii.quote_specs[0])
 	case DoubleQuotedStyle:
 		b.WriteString(ii.quote_specs[2])
-	case StrongStyle:
-		b.WriteString("<strong>")
-	case EmphasizedStyle:
-		b.WriteString("<em>")
+	default:
+		open, _ := html_style_tags(sty)
+		b.WriteString(open)
 	}
 }
 func (ii *html_inlines) end_styled(b *bytes.Buffer) {
@@ -77,14 +92,39 @@ func (ii *html_inlines) end_styled(b *bytes.Buffer) {
 		b.WriteString(ii.quote_specs[1])
 	case DoubleQuotedStyle:
 		b.WriteString(ii.quote_specs[3])
+	default:
+		_, close := html_style_tags(sty)
+		b.WriteString(close)
+	}
+}
+
+// html_style_tags returns the opening and closing tags for styled spans.
+func html_style_tags(sty Style) (string, string) {
+	switch sty {
 	case StrongStyle:
-		b.WriteString("</strong>")
+		return "<strong>", "</strong>"
 	case EmphasizedStyle:
-		b.WriteString("</em>")
+		return "<em>", "</em>"
+	case StrikethroughStyle:
+		return "<del>", "</del>"
+	case UnderlineStyle:
+		return "<u>", "</u>"
+	case HighlightStyle:
+		return "<mark>", "</mark>"
+	case SubscriptStyle:
+		return "<sub>", "</sub>"
+	case SuperscriptStyle:
+		return "<sup>", "</sup>"
+	case SmallCapsStyle:
+		return `<span style="font-variant: small-caps">`, "</span>"
+	case KeyboardStyle:
+		return "<kbd>", "</kbd>"
+	default:
+		return "", ""
 	}
 }
 func (ii *html_inlines) begin_link(b *bytes.Buffer, url RawContent) {
-	fmt.Fprintf(b, "<a href=\"%s\">", url)
+	html_link_open(b, url, nil, &ii.external_links)
 	ii.in_link = true
 }
 func (ii *html_inlines) end_link(b *bytes.Buffer) {
@@ -112,3 +152,31 @@ func html_scramble(b *bytes.Buffer, s string) {
 	}
 	b.WriteString(s[o:n])
 }
+
+func (ii *html_inlines) attr_span(b *bytes.Buffer, aa *Attrs, content RawContent) {
+	b.WriteString("<span" + html_attrs(aa) + ">")
+	b.Write(content)
+	b.WriteString("</span>")
+}
+
+func (ii *html_inlines) colored(b *bytes.Buffer, c Color, content RawContent) {
+	b.WriteString("<span style=\"color: " + html_attr_escape(c.css()) + "\">")
+	b.Write(content)
+	b.WriteString("</span>")
+}
+
+func (ii *html_inlines) badge(b *bytes.Buffer, v *badge_wrapper) {
+	b.WriteString("<span class=\"badge\" style=\"background-color: " + html_attr_escape(v.color.css()) + "; color: #fff\">")
+	html_scramble(b, v.caption())
+	b.WriteString("</span>")
+}
+
+func (ii *html_inlines) term(b *bytes.Buffer, term, definition string, first bool) {
+	if definition == "" {
+		ii.put_str(b, term)
+		return
+	}
+	b.WriteString("<abbr title=\"" + html_attr_escape(definition) + "\">")
+	ii.put_str(b, term)
+	b.WriteString("</abbr>")
+}
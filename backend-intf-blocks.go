@@ -9,6 +9,31 @@ const (
 	mlist
 )
 
+// list_task specifies whether a list item is a task with a checkbox.
+type list_task int
+
+const (
+	task_none = list_task(iota)
+	task_open
+	task_done
+)
+
+func make_task(checked bool) list_task {
+	return pick(checked, task_open, task_done)
+}
+
+// marker returns the plain text checkbox marker: `[ ] ` or `[x] `.
+func (t list_task) marker() string {
+	switch t {
+	case task_open:
+		return "[ ] "
+	case task_done:
+		return "[x] "
+	default:
+		return ""
+	}
+}
+
 // blocks is an internal interface to be implemented by markout backends
 // for structural block level formatting.
 type blocks interface {
@@ -22,22 +47,33 @@ type blocks interface {
 	close()
 	para(RawContent)
 
-	heading(counters []int, s RawContent, aa *Attrs)
+	heading(h *heading_info)
 	sect_level_in()
 	sect_level_out()
 	sect_counters() []int
 
-	begin_table(columns ...RawContent)
-	table_row(cells ...RawContent)
+	begin_table(opts table_opts, columns ...table_cell)
+	table_row(cells ...table_cell)
 	end_table()
 
 	list_title(RawContent)
-	list_level_in(initial int, broad bool) // initial counter 0 for ordered, -1 for unordered
+	list_level_in(initial int, broad bool, style NumberStyle) // initial counter 0 for ordered, -1 for unordered
 	list_level_out()
 	list_level_info() (counters []int, broads []bool)
-	list_item(counters []int, broad bool, s ...RawContent)
+	list_item(counters []int, broad bool, task list_task, s ...RawContent)
 	list_level_start(counters []int, from_broad bool)
 	list_level_done(counters []int, to_broad bool)
 
 	codeblock(lang string, s RawContent)
+	raw_block(s RawContent)
+	math_block(tex string) error
+	diagram(kind, source string) error
+	thematic_break()
+	page_break()
+
+	// placeholder_block writes a block which content is resolved when the
+	// writer is closed.
+	placeholder_block(resolve func() RawContent)
+	defer_output() *deferred_output
+	toc(entries []*heading_info, max_level int) RawContent
 }
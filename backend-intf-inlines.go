@@ -15,19 +15,37 @@ const (
 // for inline content formatting.
 type inlines interface {
 	close() error
+	format() string
 
 	current_mode() imode
 	check_mode(imode)
 	check_not_mode(imode)
+	autolink_active() bool
+	suppress_autolink(bool)
+	emoji() Emoji
+	suppress_smart(bool)
+	text_context(b *bytes.Buffer) rune
+	mark_text(b *bytes.Buffer, prev rune)
+	end_content(b *bytes.Buffer, final bool) // the content written into b is complete
 
 	put_str(*bytes.Buffer, string)
 	put_raw(*bytes.Buffer, RawContent)
 	code_str(*bytes.Buffer, string)
 	code_raw(*bytes.Buffer, RawContent)
 	codeblock_line(*bytes.Buffer, string)
+	math(b *bytes.Buffer, tex string)
+	line_break(*bytes.Buffer)
 	begin_link(*bytes.Buffer, RawContent)
 	end_link(*bytes.Buffer)
 	begin_styled(b *bytes.Buffer, sty Style)
 	end_styled(*bytes.Buffer)
-	simple_link(b *bytes.Buffer, caption RawContent, url RawContent)
+	simple_link(b *bytes.Buffer, caption RawContent, url RawContent, la *LinkAttrs)
+	anchor_link(b *bytes.Buffer, caption RawContent, id string)
+	attr_span(b *bytes.Buffer, aa *Attrs, content RawContent)
+	colored(b *bytes.Buffer, c Color, content RawContent)
+	badge(b *bytes.Buffer, v *badge_wrapper)
+	term(b *bytes.Buffer, term, definition string, first bool)
+
+	document() *document
+	set_document(*document)
 }
@@ -3,6 +3,7 @@ package markout
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"sort"
 	"strconv"
 	"strings"
@@ -10,6 +11,8 @@ import (
 
 type md_blocks struct {
 	base_blocks
+	html_spans bool          // write tables with spanned cells in HTML
+	link_refs  *md_link_refs // nil for inline links
 }
 
 func (bb *md_blocks) para(s RawContent) {
@@ -17,40 +20,100 @@ func (bb *md_blocks) para(s RawContent) {
 	bb.want_emptyln()
 }
 
-func (bb *md_blocks) heading(counters []int, s RawContent, aa *Attrs) {
+func (bb *md_blocks) heading(h *heading_info) {
+	if bb.link_refs != nil && bb.link_refs.style == MDLinksPerSection && bb.enabled() {
+		bb.link_definitions()
+	}
 	if bb.enabled() {
-		level := len(counters)
 		b := bytes.Buffer{}
-		wrepeat(&b, level, []byte("########"))
+		wrepeat(&b, h.level(), []byte("########"))
 		b.WriteByte(' ')
-		b.Write(s)
-		if aa != nil {
-			segments := []string{}
-			if aa.Identifier != "" {
-				segments = append(segments, "#"+aa.Identifier)
-			}
-			for _, c := range aa.Classes {
-				segments = append(segments, "."+c)
-			}
-			if len(aa.KeyVals) > 0 {
-				kvs := []string{}
-				for k, v := range aa.KeyVals {
-					kvs = append(kvs, fmt.Sprintf("%s=%s", k, v))
-				}
-				sort.Strings(kvs)
-				segments = append(segments, kvs...)
-			}
-			if len(segments) > 0 {
-				b.WriteString(" {")
-				b.WriteString(strings.Join(segments, " "))
-				b.WriteByte('}')
-			}
+		b.Write(h.numbered(h.caption))
+		if t := md_attrs(h.attrs); t != "" {
+			b.WriteByte(' ')
+			b.WriteString(t)
 		}
 		bb.putblock(b.Bytes())
 	}
 	bb.want_emptyln()
 }
 
+// link_definitions writes the definitions of the reference-style links
+// collected so far.
+func (bb *md_blocks) link_definitions() {
+	if s := bb.link_refs.definitions(); s != nil {
+		bb.putblock(s)
+		bb.want_emptyln()
+	}
+}
+
+// abbreviations writes the PHP Markdown Extra abbreviation definitions:
+// `*[TERM]: definition`.
+func (bb *md_blocks) abbreviations(ee []glossary_entry) {
+	b := bytes.Buffer{}
+	for _, e := range ee {
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString("*[" + e.term + "]: " + e.definition)
+	}
+	if b.Len() > 0 {
+		bb.putblock(b.Bytes())
+		bb.want_emptyln()
+	}
+}
+
+// md_attrs formats attributes in the pandoc/kramdown style:
+// `{#id .class key=value}`, returns an empty string if there are none.
+func md_attrs(aa *Attrs) string {
+	if aa == nil {
+		return ""
+	}
+	segments := []string{}
+	if aa.Identifier != "" {
+		segments = append(segments, "#"+aa.Identifier)
+	}
+	for _, c := range aa.Classes {
+		segments = append(segments, "."+c)
+	}
+	if len(aa.KeyVals) > 0 {
+		kvs := []string{}
+		for k, v := range aa.KeyVals {
+			if v == "" || strings.ContainsAny(v, " \t\"'{}") {
+				kvs = append(kvs, fmt.Sprintf("%s=%q", k, v))
+			} else {
+				kvs = append(kvs, fmt.Sprintf("%s=%s", k, v))
+			}
+		}
+		sort.Strings(kvs)
+		segments = append(segments, kvs...)
+	}
+	if len(segments) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(segments, " ") + "}"
+}
+
+// toc writes a nested list of links to section headings.
+func (bb *md_blocks) toc(entries []*heading_info, max_level int) RawContent {
+	b := bytes.Buffer{}
+	for _, h := range entries {
+		if max_level > 0 && h.level() > max_level {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		wrepeat(&b, 2*(h.level()-1), nil)
+		b.WriteString("- [")
+		b.Write(h.numbered(h.linkable))
+		b.WriteString("](#")
+		b.WriteString(h.id)
+		b.WriteByte(')')
+	}
+	return b.Bytes()
+}
+
 func (bb *md_blocks) list_title(s RawContent) {
 	bb.putblock(s)
 	bb.want_emptyln()
@@ -67,7 +130,7 @@ func (bb *md_blocks) list_level_done(counters []int, to_broad bool) {
 	}
 }
 
-func (bb *md_blocks) list_item(counters []int, broad bool, s ...RawContent) {
+func (bb *md_blocks) list_item(counters []int, broad bool, task list_task, s ...RawContent) {
 	if bb.enabled() {
 		level := len(counters)
 		counter := counters[level-1]
@@ -79,23 +142,21 @@ func (bb *md_blocks) list_item(counters []int, broad bool, s ...RawContent) {
 			ln = []byte{' '}
 		}
 
-		var ind int
-		if counter < 0 {
-			// unordered
-			const prefix = "- "
-			bb.putblock_ex(level-1, prefix, ln, "")
-			ind = len(prefix)
-		} else {
+		prefix := "- "
+		if counter >= 0 {
 			// ordered
-			num := strconv.FormatInt(int64(counter), 10) + ". "
-			bb.putblock_ex(level-1, num, ln, "")
-			ind = len(num)
+			prefix = strconv.FormatInt(int64(counter), 10) + ". "
 		}
+		ind_str := strings.Repeat(" ", len(prefix))
+		// line breaks continue at the item content indentation, after the
+		// task checkbox; the following paragraphs must not be indented
+		// further, which would turn them into code blocks
+		lead := strings.Repeat("  ", level-1) + ind_str + strings.Repeat(" ", len(task.marker()))
+		bb.putblock_ex(level-1, prefix+task.marker(), indent_lines(ln, lead), "")
 		if len(s) > 1 {
-			ind_str := strings.Repeat(" ", ind)
 			for _, ln = range s[1:] {
 				bb.want_emptyln()
-				bb.putblock_ex(level-1, ind_str, ln, "")
+				bb.putblock_ex(level-1, ind_str, indent_lines(ln, lead), "")
 			}
 		}
 	}
@@ -106,26 +167,169 @@ func (bb *md_blocks) list_item(counters []int, broad bool, s ...RawContent) {
 	}
 }
 
-func (bb *md_blocks) end_table() {
-	if len(bb.table) > 1 {
-		ww := bb.table.measure_cells(bb.table[0], nil)
-
-		eol := []byte{'\n'}
-		rdecor := table_decor{[]byte("|-"), []byte("-|-"), nil}
-		cdecor := table_decor{[]byte("| "), []byte(" | "), nil}
-		rule := []byte("--------")
+func (bb *md_blocks) table_row(cells ...table_cell) {
+	bb.base_blocks.table_row(cells...)
+	if bb.table_opts.streaming {
+		bb.flush_table(false)
+	}
+}
 
+func (bb *md_blocks) end_table() {
+	if len(bb.table) > 1 && bb.table_stream.written == 0 && bb.html_spans && bb.table.has_spans() {
 		bb.do_nextline()
-		bb.table.print_row(bb.out, bb.table[0], &cdecor, ww)
-		bb.out.Write(eol)
-		bb.table.print_rule(bb.out, rule, &rdecor, ww)
-		for _, row := range bb.table[1:] {
+		html_table(bb.out, bb.table, &bb.table_opts, md_html_cell_content)
+	} else if bb.table_stream.written > 0 || len(bb.table) > 1 && !bb.defer_table(bb.flush_table) {
+		bb.flush_table(true)
+	}
+	bb.table = bb.table[:0]
+	bb.want_emptyln()
+}
+
+// flush_table writes pending table rows. Streaming tables can not fall back
+// to HTML, spanned content is always repeated.
+func (bb *md_blocks) flush_table(final bool) {
+	cdecor := table_decor{[]byte("| "), []byte(" | "), nil}
+	opts := &bb.table_opts
+	st := &bb.table_stream
+	st.add(bb.table, md_cell_lines, true)
+	if !st.ready(opts, len(cdecor.c), final) {
+		return
+	}
+	eol := []byte{'\n'}
+	for _, row := range st.take() {
+		if st.written == 0 {
+			if len(opts.caption) > 0 {
+				bb.putblock(opts.caption)
+				bb.want_emptyln()
+			}
+			bb.do_nextline()
+			print_row(bb.out, row, 0, &cdecor, st.widths, opts)
+			bb.out.Write(eol)
+			md_table_rule(bb.out, st.widths, opts)
+		} else {
 			bb.out.Write(eol)
-			bb.table.print_row(bb.out, row, &cdecor, nil)
+			print_row(bb.out, row, 0, &cdecor, st.widths, opts)
 		}
-		bb.table = bb.table[:0]
+		st.written++
 	}
-	bb.want_emptyln()
+	bb.table_flushed()
+}
+
+// md_html_cell_content writes table cells of the html fallback. Markdown is
+// not parsed within html blocks, content with markdown syntax is written as
+// separate blocks surrounded by empty lines.
+func md_html_cell_content(w io.Writer, c *table_cell) {
+	if c.simple() && bytes.IndexAny(c.blocks[0].content, md_cell_syntax) < 0 {
+		w.Write(c.blocks[0].content)
+		return
+	}
+	b := bytes.Buffer{}
+	b.WriteString("\n\n")
+	indents := []int{} // content indentation of the enclosing list items
+	for i, blk := range c.blocks {
+		if i > 0 && !(blk.is_item() && c.blocks[i-1].is_item()) {
+			b.WriteByte('\n')
+		}
+		if !blk.is_item() {
+			indents = indents[:0]
+			b.Write(blk.content)
+			b.WriteByte('\n')
+			continue
+		}
+		if len(indents) >= blk.level {
+			indents = indents[:blk.level-1]
+		}
+		ind := 0
+		if len(indents) > 0 {
+			ind = indents[len(indents)-1]
+		}
+		prefix := "- "
+		if blk.counter >= 0 {
+			prefix = strconv.Itoa(blk.counter) + ". "
+		}
+		indents = append(indents, ind+len(prefix))
+		lead := strings.Repeat(" ", ind+len(prefix)+len(blk.task.marker()))
+		b.WriteString(strings.Repeat(" ", ind) + prefix + blk.task.marker())
+		b.Write(indent_lines(blk.content, lead))
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	w.Write(b.Bytes())
+}
+
+// md_cell_syntax lists the characters that markdown-formatted content may
+// rely on.
+const md_cell_syntax = "\\`*_~[]<>&!\n"
+
+// md_cell_lines joins table cell blocks into a single line, line breaks are
+// converted to `<br>` and pipes are escaped.
+func md_cell_lines(c *table_cell) []RawContent {
+	b := bytes.Buffer{}
+	for i, blk := range c.blocks {
+		if i > 0 {
+			if blk.is_item() && c.blocks[i-1].is_item() {
+				b.WriteString("<br>")
+			} else {
+				b.WriteString("<br><br>")
+			}
+		}
+		if blk.is_item() {
+			wrepeat(&b, 12*(blk.level-1), []byte("&nbsp;&nbsp;"))
+			if blk.counter < 0 {
+				b.WriteString("- ")
+			} else {
+				b.WriteString(strconv.Itoa(blk.counter) + ". ")
+			}
+			b.WriteString(blk.task.marker())
+		}
+		for k, ch := range blk.content {
+			switch {
+			case ch == '\n':
+				if md_escaped(blk.content[:k]) {
+					// hard line break: `\` followed by newline
+					b.Truncate(b.Len() - 1)
+				}
+				b.WriteString("<br>")
+			case ch == '|' && (k == 0 || blk.content[k-1] != '\\'):
+				b.WriteString("\\|")
+			default:
+				b.WriteByte(ch)
+			}
+		}
+	}
+	return []RawContent{b.Bytes()}
+}
+
+// md_escaped returns true if s ends with an odd number of backslashes,
+// escaping the character that follows.
+func md_escaped(s []byte) bool {
+	n := 0
+	for n < len(s) && s[len(s)-1-n] == '\\' {
+		n++
+	}
+	return n%2 == 1
+}
+
+// md_table_rule writes the delimiter row that separates table header from the
+// table body, alignment is marked with colons: `|:---|:---:|---:`
+func md_table_rule(w io.Writer, col_widths []int, opts *table_opts) {
+	b := bytes.Buffer{}
+	for i, width := range col_widths {
+		b.WriteByte('|')
+		lc, rc := byte('-'), byte('-')
+		switch opts.align(i) {
+		case AlignLeft:
+			lc = ':'
+		case AlignCenter:
+			lc, rc = ':', ':'
+		case AlignRight:
+			rc = ':'
+		}
+		b.WriteByte(lc)
+		wrepeat(&b, width, []byte("--------"))
+		b.WriteByte(rc)
+	}
+	w.Write(b.Bytes())
 }
 
 func (bb *md_blocks) codeblock(lang string, s RawContent) {
@@ -140,3 +344,34 @@ func (bb *md_blocks) codeblock(lang string, s RawContent) {
 	bb.out.Write([]byte("\n```"))
 	bb.want_emptyln()
 }
+
+// thematic_break relies on paragraphs being followed by empty lines,
+// otherwise `---` would turn the preceding paragraph into a setext heading.
+func (bb *md_blocks) thematic_break() {
+	bb.putblock(RawContent("---"))
+	bb.want_emptyln()
+}
+
+func (bb *md_blocks) page_break() {
+	bb.putblock(RawContent(html_page_break))
+	bb.want_emptyln()
+}
+
+func (bb *md_blocks) math_block(tex string) error {
+	bb.putblock(RawContent("$$\n" + source_lines(tex) + "\n$$"))
+	bb.want_emptyln()
+	return nil
+}
+
+// diagram writes a fenced block, the fence is longer than any backtick
+// sequence within the source.
+func (bb *md_blocks) diagram(kind, source string) error {
+	source = source_lines(source)
+	fence := "```"
+	for strings.Contains(source, fence) {
+		fence += "`"
+	}
+	bb.putblock(RawContent(fence + kind + "\n" + source + "\n" + fence))
+	bb.want_emptyln()
+	return nil
+}
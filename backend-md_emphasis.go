@@ -0,0 +1,198 @@
+package markout
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/exp/slices"
+)
+
+// MDEmphasis specifies how emphasized and strong spans are written in
+// markdown.
+type MDEmphasis int
+
+const (
+	MDEmphasisHTML       = MDEmphasis(iota) // <em> and <strong> tags
+	MDEmphasisAsterisk                      // *em* and **strong**
+	MDEmphasisUnderscore                    // _em_ and __strong__, asterisks are used within words
+)
+
+// md_span is a styled span that is being written.
+type md_span struct {
+	offset int  // buffer offset where the span content begins
+	native bool // delimiters are inserted when the span ends
+}
+
+// md_closer is a closing delimiter run that may fail to close its span
+// depending on the character that follows it.
+type md_closer struct {
+	buf         *bytes.Buffer
+	sty         Style
+	open, olen  int  // opening delimiter position and length
+	close, clen int  // closing delimiter position and length
+	star        bool // underscores can be replaced with asterisks
+}
+
+func (c *md_closer) end() int {
+	return c.close + c.clen
+}
+
+// fallback replaces the delimiters with the ones that close regardless of
+// the following character: asterisks for underscores, or HTML tags.
+func (c *md_closer) fallback() {
+	if c.star {
+		bb := c.buf.Bytes()
+		copy(bb[c.open:c.open+c.olen], "**")
+		copy(bb[c.close:c.end()], "**")
+	} else {
+		c.to_html()
+	}
+}
+
+// to_html replaces the delimiters with HTML tags.
+func (c *md_closer) to_html() {
+	s := slices.Clone(c.buf.Bytes()[c.open:])
+	open_tag, close_tag := html_style_tags(c.sty)
+	c.buf.Truncate(c.open)
+	c.buf.WriteString(open_tag)
+	c.buf.Write(s[c.olen : c.close-c.open])
+	c.buf.WriteString(close_tag)
+	c.buf.Write(s[c.end()-c.open:])
+}
+
+// md_punct returns true for the characters that CommonMark treats as
+// punctuation when checking delimiter runs.
+func md_punct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// md_boundary returns true for the characters that allow delimiter runs
+// to open or close next to punctuation: whitespace, punctuation, and the
+// beginning or end of the content (utf8.RuneError).
+func md_boundary(r rune) bool {
+	return r == utf8.RuneError || unicode.IsSpace(r) || md_punct(r)
+}
+
+func (ii *md_inlines) native_emphasis(sty Style) bool {
+	return ii.emphasis != MDEmphasisHTML && (sty == StrongStyle || sty == EmphasizedStyle)
+}
+
+// settle checks the pending closing delimiter against the character that
+// follows it, falling back to HTML tags if the delimiter can not close.
+func (ii *md_inlines) settle(b *bytes.Buffer) {
+	c := ii.pending
+	if c == nil || c.buf != b || b.Len() <= c.end() {
+		return
+	}
+	ii.pending = nil
+	if r, _ := utf8.DecodeRune(b.Bytes()[c.end():]); !md_boundary(r) {
+		c.fallback()
+	}
+}
+
+// end_content resolves the pending closing delimiter when the content written
+// into b is complete. The end of a block is a valid boundary, otherwise the
+// content is embedded into other content and the next character is not
+// known, so the delimiter is replaced with a safe alternative.
+func (ii *md_inlines) end_content(b *bytes.Buffer, final bool) {
+	c := ii.pending
+	if c == nil || c.buf != b {
+		return
+	}
+	ii.pending = nil
+	if !final {
+		c.fallback()
+	}
+}
+
+// settle_delimiter resolves the pending closing delimiter when it is
+// followed by another delimiter or tag.
+func (ii *md_inlines) settle_delimiter(b *bytes.Buffer) {
+	if c := ii.pending; c != nil && c.buf == b && b.Len() == c.end() {
+		ii.pending = nil
+	}
+}
+
+// begin_native starts an emphasis span, the delimiters are chosen when the
+// span ends.
+func (ii *md_inlines) begin_native(b *bytes.Buffer) {
+	ii.settle(b)
+	ii.settle_delimiter(b)
+	ii.spans = append(ii.spans, md_span{offset: b.Len(), native: true})
+}
+
+// end_native writes the delimiters around the span content. Whitespace is
+// moved outside of the span, the delimiter character is chosen from
+// the adjacent characters, and HTML tags are used when no delimiter can
+// be used safely.
+func (ii *md_inlines) end_native(b *bytes.Buffer, sty Style, offset int) {
+	ii.settle(b)
+	ii.settle_delimiter(b)
+	content := slices.Clone(b.Bytes()[offset:])
+	b.Truncate(offset)
+
+	inner := bytes.TrimLeftFunc(content, unicode.IsSpace)
+	b.Write(content[:len(content)-len(inner)])
+	trimmed := bytes.TrimRightFunc(inner, unicode.IsSpace)
+	trail := inner[len(trimmed):]
+	if len(trimmed) == 0 {
+		b.Write(trail)
+		return
+	}
+
+	// the opening delimiter of an enclosing span that begins at the same
+	// offset is inserted later, it acts as punctuation
+	prev := utf8.RuneError
+	if b.Len() > 0 && !ii.opens_at(offset) {
+		prev, _ = utf8.DecodeLastRune(b.Bytes())
+	}
+	first, _ := utf8.DecodeRune(trimmed)
+	last, _ := utf8.DecodeLastRune(trimmed)
+
+	// opening runs must be left-flanking, underscores may not open
+	// within words
+	ch := byte('*')
+	if ii.emphasis == MDEmphasisUnderscore && md_boundary(prev) {
+		ch = '_'
+	}
+	can_open := !md_punct(first) || md_boundary(prev)
+	if !can_open || prev == rune(ch) {
+		open_tag, close_tag := html_style_tags(sty)
+		b.WriteString(open_tag)
+		b.Write(trimmed)
+		b.WriteString(close_tag)
+		b.Write(trail)
+		return
+	}
+
+	n := pick(sty == StrongStyle, 1, 2)
+	delim := bytes.Repeat([]byte{ch}, n)
+	c := &md_closer{buf: b, sty: sty, open: b.Len(), olen: n}
+	b.Write(delim)
+	b.Write(trimmed)
+	c.close, c.clen = b.Len(), n
+	b.Write(delim)
+	b.Write(trail)
+
+	// closing runs must be right-flanking, underscores may not close
+	// within words; this depends on the character that follows
+	c.star = ch == '_' && !md_punct(last) && prev != '*'
+	if ch == '_' || md_punct(last) {
+		if len(trail) > 0 {
+			return // followed by whitespace
+		}
+		ii.pending = c
+	}
+}
+
+// opens_at returns true if an enclosing native span begins at offset, its
+// opening delimiter is inserted later.
+func (ii *md_inlines) opens_at(offset int) bool {
+	for _, s := range ii.spans {
+		if s.native && s.offset == offset {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package markout
 import (
 	"bytes"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"golang.org/x/exp/slices"
@@ -12,8 +13,15 @@ const want_backslash_escaped = "\\`*_{}[]()#+-.!<>"
 
 type md_inlines struct {
 	base_inlines
-	html_links   bool
-	pending_link *RawContent
+	html_links      bool
+	link_refs       *md_link_refs // nil for inline links
+	pending_link    *RawContent
+	emphasis        MDEmphasis
+	bracketed_spans bool
+	badges          MDBadges
+	abbreviations   bool // terms are defined with `*[TERM]: definition`
+	spans           []md_span
+	pending         *md_closer // closing delimiter that depends on the next character
 }
 
 func md_scramble(b *bytes.Buffer, s string) {
@@ -51,6 +59,10 @@ func md_scramble_code(b *bytes.Buffer, s string) {
 	}
 }
 
+func (ii *md_inlines) format() string {
+	return FormatMD
+}
+
 func (ii *md_inlines) current_mode() imode {
 	return pick(ii.pending_link != nil, iflow, iflow|ilink)
 }
@@ -80,24 +92,45 @@ func (ii *md_inlines) close() error {
 }
 
 func (ii *md_inlines) put_raw(b *bytes.Buffer, s RawContent) {
+	defer ii.settle(b)
 	b.Write(s)
 }
 func (ii *md_inlines) put_str(b *bytes.Buffer, s string) {
-	md_scramble(b, s)
+	defer ii.settle(b)
+	md_scramble(b, ii.smarten(b, s))
 }
 func (ii *md_inlines) code_raw(b *bytes.Buffer, s RawContent) {
+	defer ii.settle(b)
 	b.WriteByte('`')
 	b.Write(s)
 	b.WriteByte('`')
 }
 func (ii *md_inlines) code_str(b *bytes.Buffer, s string) {
+	defer ii.settle(b)
 	md_scramble_code(b, s)
 }
 func (ii *md_inlines) codeblock_line(b *bytes.Buffer, s string) {
 	b.Write([]byte(s)) // todo: deal with "```"
 }
+func (ii *md_inlines) line_break(b *bytes.Buffer) {
+	defer ii.settle(b)
+	b.WriteString("\\\n")
+}
+func (ii *md_inlines) math(b *bytes.Buffer, tex string) {
+	defer ii.settle(b)
+	// no spaces are allowed after the opening and before the closing `$`
+	b.WriteByte('$')
+	b.WriteString(strings.TrimSpace(tex))
+	b.WriteByte('$')
+}
 func (ii *md_inlines) begin_styled(b *bytes.Buffer, sty Style) {
 	ii.start_styled(sty)
+	if ii.native_emphasis(sty) {
+		ii.begin_native(b)
+		return
+	}
+	defer ii.settle(b)
+	ii.spans = append(ii.spans, md_span{offset: b.Len()})
 	switch sty {
 	case SingleQuotedStyle:
 		b.WriteString(ii.quote_specs[0])
@@ -107,10 +140,23 @@ func (ii *md_inlines) begin_styled(b *bytes.Buffer, sty Style) {
 		b.WriteString("<strong>") // "**" or "__" is not reliable enough
 	case EmphasizedStyle:
 		b.WriteString("<em>") // "*" or "_" is not reliable enough
+	case StrikethroughStyle:
+		b.WriteString("~~") // GFM extension
+	default:
+		open, _ := html_style_tags(sty)
+		b.WriteString(open)
 	}
 }
 func (ii *md_inlines) end_styled(b *bytes.Buffer) {
 	sty := ii.finish_styled()
+	n := len(ii.spans) - 1
+	span := ii.spans[n]
+	ii.spans = ii.spans[:n]
+	if span.native {
+		ii.end_native(b, sty, span.offset)
+		return
+	}
+	defer ii.settle(b)
 	switch sty {
 	case SingleQuotedStyle:
 		b.WriteString(ii.quote_specs[1])
@@ -120,36 +166,122 @@ func (ii *md_inlines) end_styled(b *bytes.Buffer) {
 		b.WriteString("</strong>")
 	case EmphasizedStyle:
 		b.WriteString("</em>")
+	case StrikethroughStyle:
+		b.WriteString("~~")
+	default:
+		_, close := html_style_tags(sty)
+		b.WriteString(close)
 	}
 }
 func (ii *md_inlines) begin_link(b *bytes.Buffer, url RawContent) {
+	defer ii.settle(b)
 	if ii.html_links {
-		fmt.Fprintf(b, "<a href=\"%s\">", url)
+		html_link_open(b, url, nil, nil)
 	} else {
 		b.WriteByte('[')
 	}
 	ii.pending_link = &url
 }
 func (ii *md_inlines) end_link(b *bytes.Buffer) {
+	defer ii.settle(b)
 	if ii.html_links {
 		b.WriteString("</a>")
 	} else {
-		b.WriteString("](")
-		b.Write(*ii.pending_link)
-		b.WriteByte(')')
+		ii.link_target(b, *ii.pending_link, "")
 	}
 	ii.pending_link = nil
 }
-func (ii *md_inlines) simple_link(b *bytes.Buffer, caption RawContent, url RawContent) {
-	if len(caption) == 0 || slices.Equal(caption, url) {
+func (ii *md_inlines) simple_link(b *bytes.Buffer, caption RawContent, url RawContent, la *LinkAttrs) {
+	defer ii.settle(b)
+	title := ""
+	if la != nil {
+		title = la.Title
+	}
+	switch {
+	case ii.html_links:
+		html_link_open(b, url, la, nil)
+		b.Write(pick(len(caption) == 0, caption, url))
+		b.WriteString("</a>")
+	case ii.link_refs == nil && title == "" && (len(caption) == 0 || slices.Equal(caption, url)):
 		b.WriteByte('[')
 		b.Write(url)
 		b.WriteByte(']')
+	default:
+		b.WriteByte('[')
+		b.Write(pick(len(caption) == 0, caption, url))
+		ii.link_target(b, url, title)
+	}
+}
+
+// link_target closes the link caption and writes either the inline target
+// `](url "title")` or the reference label `][n]`.
+func (ii *md_inlines) link_target(b *bytes.Buffer, url RawContent, title string) {
+	if ii.link_refs != nil {
+		b.WriteString("][" + strconv.Itoa(ii.link_refs.label(url, title)) + "]")
+		return
+	}
+	b.WriteString("](")
+	b.Write(url)
+	if title != "" {
+		b.WriteString(" " + md_link_title(title))
+	}
+	b.WriteByte(')')
+}
+
+func (ii *md_inlines) anchor_link(b *bytes.Buffer, caption RawContent, id string) {
+	defer ii.settle(b)
+	if ii.html_links {
+		fmt.Fprintf(b, "<a href=\"#%s\">", id)
+		b.Write(caption)
+		b.WriteString("</a>")
 	} else {
 		b.WriteByte('[')
 		b.Write(caption)
+		b.WriteString("](#")
+		b.WriteString(id)
+		b.WriteByte(')')
+	}
+}
+
+// attr_span writes pandoc bracketed spans: `[content]{.class}`
+func (ii *md_inlines) attr_span(b *bytes.Buffer, aa *Attrs, content RawContent) {
+	defer ii.settle(b)
+	t := md_attrs(aa)
+	if !ii.bracketed_spans || t == "" {
+		b.Write(content)
+		return
+	}
+	b.WriteByte('[')
+	b.Write(content)
+	b.WriteByte(']')
+	b.WriteString(t)
+}
+
+// colored writes the content without colors, markdown does not support them.
+func (ii *md_inlines) colored(b *bytes.Buffer, c Color, content RawContent) {
+	defer ii.settle(b)
+	b.Write(content)
+}
+
+func (ii *md_inlines) badge(b *bytes.Buffer, v *badge_wrapper) {
+	defer ii.settle(b)
+	if ii.badges == MDBadgesShields {
+		b.WriteString("![")
+		md_scramble(b, v.caption())
 		b.WriteString("](")
-		b.Write(url)
+		b.WriteString(v.shields_url())
 		b.WriteByte(')')
+	} else {
+		md_scramble(b, "["+v.caption()+"]")
+	}
+}
+
+// term writes the term as-is when the abbreviations are defined at the end of
+// the document, otherwise the term is expanded on its first use.
+func (ii *md_inlines) term(b *bytes.Buffer, term, definition string, first bool) {
+	defer ii.settle(b)
+	md_scramble(b, term)
+	if !ii.abbreviations && first && definition != "" {
+		md_scramble(b, " ("+definition+")")
 	}
 }
@@ -30,3 +30,61 @@ func Test_md_scramble(t *testing.T) {
 		})
 	}
 }
+
+func Test_md_emphasis(t *testing.T) {
+	tests := []struct {
+		emphasis MDEmphasis
+		arg      []any
+		want     string
+	}{
+		{MDEmphasisHTML, []any{Emphasized("a")}, "<em>a</em>"},
+		{MDEmphasisAsterisk, []any{Strong("bold")}, "**bold**"},
+		{MDEmphasisAsterisk, []any{"a", Strong(" b "), "c"}, "a **b** c"},
+		{MDEmphasisAsterisk, []any{"a", Strong(" "), "b"}, "a b"},
+		{MDEmphasisAsterisk, []any{"un", Emphasized("frigging"), "believable"}, "un*frigging*believable"},
+		{MDEmphasisAsterisk, []any{"x ", Strong("a."), "b"}, `x <strong>a\.</strong>b`},
+		{MDEmphasisAsterisk, []any{"x ", Strong("a."), " b"}, `x **a\.** b`},
+		{MDEmphasisAsterisk, []any{"x", Strong("(a)"), " y"}, `x<strong>\(a\)</strong> y`},
+		{MDEmphasisAsterisk, []any{Strong(Emphasized("x"))}, "***x***"},
+		{MDEmphasisAsterisk, []any{Emphasized("a"), Emphasized("b")}, "*a*<em>b</em>"},
+		{MDEmphasisUnderscore, []any{"a ", Emphasized("b"), " c"}, "a _b_ c"},
+		{MDEmphasisUnderscore, []any{"un", Emphasized("frigging"), "believable"}, "un*frigging*believable"},
+		{MDEmphasisUnderscore, []any{Strong("b"), "c"}, "**b**c"},
+		{MDEmphasisUnderscore, []any{Strong("b."), "c"}, `<strong>b\.</strong>c`},
+		{MDEmphasisAsterisk, []any{printf_callback("%sb", Strong("a."))}, `<strong>a\.</strong>b`},
+		{MDEmphasisAsterisk, []any{printf_callback("%s b", Strong("a."))}, `<strong>a\.</strong> b`},
+		{MDEmphasisUnderscore, []any{printf_callback("%sc", Strong("b"))}, "**b**c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			b := bytes.Buffer{}
+			ii := &md_inlines{emphasis: tt.emphasis}
+			to_buffer(&b, ii, nil, func(p Printer) {
+				for _, a := range tt.arg {
+					p.Print(a)
+				}
+			})
+			if got := b.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_md_emphasis_blocks(t *testing.T) {
+	b := bytes.Buffer{}
+	w := NewMD(&b, MDOptions{Emphasis: MDEmphasisAsterisk})
+	w.Para(Strong("a."))
+	w.Para("xxxxxxxxxxxxxxxxxxxxxxxxxxx")
+	w.BeginTable("A", "B")
+	w.TableRow(Strong("c."), "yyyyyyyyyyyyyyyyyyyy")
+	w.EndTable()
+	w.Close()
+	want := "**a\\.**\n\nxxxxxxxxxxxxxxxxxxxxxxxxxxx\n\n" +
+		"| A       | B\n" +
+		"|---------|----------------------\n" +
+		"| **c\\.** | yyyyyyyyyyyyyyyyyyyy\n\n"
+	if got := b.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
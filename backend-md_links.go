@@ -0,0 +1,72 @@
+package markout
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// MDLinkStyle specifies how links are written in markdown.
+type MDLinkStyle int
+
+const (
+	MDLinksInline      = MDLinkStyle(iota) // inline links: [caption](url "title")
+	MDLinksPerSection                      // reference links, defined before the next section heading
+	MDLinksPerDocument                     // reference links, defined at the end of the document
+)
+
+// md_link_refs collects the definitions of reference-style links. Labels are
+// numbered throughout the document, links with the same URL and title share
+// the label.
+type md_link_refs struct {
+	style   MDLinkStyle
+	labels  map[string]int
+	pending []md_link_def // definitions that are not yet written out
+}
+
+type md_link_def struct {
+	label int
+	url   string
+	title string
+}
+
+// label returns the reference label for the link, registering its definition
+// on first use.
+func (r *md_link_refs) label(url RawContent, title string) int {
+	key := string(url) + "\n" + title
+	if n, ok := r.labels[key]; ok {
+		return n
+	}
+	if r.labels == nil {
+		r.labels = map[string]int{}
+	}
+	n := len(r.labels) + 1
+	r.labels[key] = n
+	r.pending = append(r.pending, md_link_def{label: n, url: string(url), title: title})
+	return n
+}
+
+// definitions formats and drains the pending definitions:
+// `[n]: url "title"`, returns nil if there are none.
+func (r *md_link_refs) definitions() RawContent {
+	if len(r.pending) == 0 {
+		return nil
+	}
+	b := bytes.Buffer{}
+	for i, d := range r.pending {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString("[" + strconv.Itoa(d.label) + "]: " + d.url)
+		if d.title != "" {
+			b.WriteString(" " + md_link_title(d.title))
+		}
+	}
+	r.pending = r.pending[:0]
+	return b.Bytes()
+}
+
+// md_link_title formats a double quoted link title.
+func md_link_title(t string) string {
+	return "\"" + strings.ReplaceAll(strings.ReplaceAll(t, "\\", "\\\\"), "\"", "\\\"") + "\""
+}
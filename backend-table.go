@@ -1,62 +1,520 @@
 package markout
 
 import (
+	"bytes"
 	"io"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/adnsv/go-markout/wcwidth"
 )
 
-type table_grid [][]RawContent
+// table_cell is a single table cell, cols and rows specify the number of grid
+// columns and rows occupied by the cell. Cell content is composed of one or
+// more blocks that are converted to lines by the backends that lay out cells
+// as multi-line boxes.
+type table_cell struct {
+	blocks []cell_block
+	lines  []RawContent
+	cols   int
+	rows   int
+}
+
+// cell_block is a paragraph or a list item within a table cell.
+type cell_block struct {
+	content RawContent
+	level   int // list nesting level, 0 for paragraphs
+	counter int // item counter for ordered lists, -1 for unordered
+	style   NumberStyle
+	task    list_task
+}
 
-func (t table_grid) measure_cell(s RawContent) int {
-	return wcwidth.StringCells(string(s))
+// is_item returns true if the cell_block is a list item.
+func (b *cell_block) is_item() bool {
+	return b.level > 0
 }
 
-func (t table_grid) measure_cells(cc []RawContent, col_widths *[]int) (widths []int) {
-	r := make([]int, len(cc))
-	for i := range r {
-		w := t.measure_cell(cc[i])
-		r[i] = w
-		if col_widths != nil {
-			if i < len(*col_widths) {
-				if w > (*col_widths)[i] {
-					(*col_widths)[i] = w
+// has_placeholders returns true if the cells or the caption contain
+// deferred output placeholders.
+func (t table_grid) has_placeholders(d *deferred_output, caption RawContent) bool {
+	if d.has_placeholders(caption) {
+		return true
+	}
+	for _, row := range t {
+		for _, c := range row {
+			for _, b := range c.blocks {
+				if d.has_placeholders(b.content) {
+					return true
 				}
-			} else {
-				*col_widths = append(*col_widths, w)
 			}
 		}
 	}
+	return false
+}
+
+// simple returns true if the cell has a single paragraph block.
+func (c *table_cell) simple() bool {
+	return len(c.blocks) == 1 && !c.blocks[0].is_item()
+}
+
+type table_grid [][]table_cell
+
+// format_cells converts cell blocks to lines.
+func (t table_grid) format_cells(f func(c *table_cell) []RawContent) {
+	for _, row := range t {
+		for i := range row {
+			row[i].lines = f(&row[i])
+		}
+	}
+}
+
+// table_opts holds table-wide layout information.
+type table_opts struct {
+	aligns      []Align
+	widths      []int // fixed column widths, 0 for measured columns
+	caption     RawContent
+	attrs       *Attrs
+	streaming   bool
+	sample_rows int
+	overflow    Overflow
+}
+
+func (o *table_opts) align(i int) Align {
+	if i < len(o.aligns) {
+		return o.aligns[i]
+	}
+	return AlignDefault
+}
+
+// table_slot is a table cell positioned within the grid. For the slots that
+// are covered by a row span from one of the preceeding rows, the covered flag
+// is set and cell points to the spanning cell.
+type table_slot struct {
+	col, cols int
+	cell      *table_cell
+	covered   bool
+}
+
+// table_layout positions cells into grid columns, keeping track of the columns
+// occupied by row spans.
+type table_layout struct {
+	row    int
+	until  []int        // per-column last row covered by a row span
+	origin []table_slot // per-column spanning slot
+}
+
+func (l *table_layout) covered(col int) bool {
+	return col < len(l.until) && l.until[col] >= l.row
+}
+
+func (l *table_layout) place(cells []table_cell) []table_slot {
+	r := make([]table_slot, 0, len(cells))
+	col := 0
+	skip_covered := func() {
+		for l.covered(col) {
+			o := l.origin[col]
+			n := o.col + o.cols - col
+			r = append(r, table_slot{col: col, cols: n, cell: o.cell, covered: true})
+			col += n
+		}
+	}
+	for i := range cells {
+		skip_covered()
+		c := &cells[i]
+		s := table_slot{col: col, cols: c.cols, cell: c}
+		if s.cols < 1 {
+			s.cols = 1
+		}
+		if c.rows > 1 {
+			for len(l.until) < col+s.cols {
+				l.until = append(l.until, -1)
+				l.origin = append(l.origin, table_slot{})
+			}
+			for k := col; k < col+s.cols; k++ {
+				l.until[k] = l.row + c.rows - 1
+				l.origin[k] = s
+			}
+		}
+		r = append(r, s)
+		col += s.cols
+	}
+	for col < len(l.until) {
+		if l.covered(col) {
+			skip_covered()
+		} else {
+			col++
+		}
+	}
+	l.row++
+	return r
+}
+
+// table_stream lays out table rows as they arrive. Rows are kept pending
+// until column widths are established: for streaming tables, this happens
+// after the sample rows are collected; for buffered tables, at the end of the
+// table.
+type table_stream struct {
+	layout  table_layout
+	added   int // number of table grid rows that are already laid out
+	pending [][]table_slot
+	widths  []int // nil until established
+	written int   // number of rows written so far, including the header
+}
+
+// add formats and lays out the table grid rows that were not seen before.
+func (st *table_stream) add(t table_grid, format func(c *table_cell) []RawContent, expand bool) {
+	if st.added >= len(t) {
+		return
+	}
+	rows := t[st.added:]
+	rows.format_cells(format)
+	slots := rows.layout_with(&st.layout)
+	if expand {
+		slots = expand_spans(slots)
+	}
+	st.pending = append(st.pending, slots...)
+	st.added = len(t)
+}
+
+// ready establishes column widths when possible and returns true if pending
+// rows can be written.
+func (st *table_stream) ready(opts *table_opts, sep int, final bool) bool {
+	if st.widths == nil {
+		sampled := opts.streaming && len(st.pending)-1 >= opts.sample_rows
+		if !final && !sampled {
+			return false
+		}
+		st.widths = measure_columns(st.pending, sep)
+		for i, w := range opts.widths {
+			for len(st.widths) <= i {
+				st.widths = append(st.widths, 0)
+			}
+			if w > 0 {
+				st.widths[i] = w
+			}
+		}
+	}
+	return true
+}
+
+// take removes and returns the pending rows.
+func (st *table_stream) take() [][]table_slot {
+	r := st.pending
+	st.pending = nil
 	return r
 }
 
+func (t table_grid) layout() [][]table_slot {
+	return t.layout_with(&table_layout{})
+}
+
+func (t table_grid) layout_with(l *table_layout) [][]table_slot {
+	r := make([][]table_slot, 0, len(t))
+	for _, row := range t {
+		r = append(r, l.place(row))
+	}
+	return r
+}
+
+func (t table_grid) has_spans() bool {
+	for _, row := range t {
+		for _, c := range row {
+			if c.cols > 1 || c.rows > 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// expand_spans converts spanned slots into sequences of single-column slots
+// that repeat the spanning content.
+func expand_spans(rows [][]table_slot) [][]table_slot {
+	r := make([][]table_slot, 0, len(rows))
+	for _, slots := range rows {
+		ss := []table_slot{}
+		for _, s := range slots {
+			for k := 0; k < s.cols; k++ {
+				ss = append(ss, table_slot{col: s.col + k, cols: 1, cell: s.cell})
+			}
+		}
+		r = append(r, ss)
+	}
+	return r
+}
+
+func measure_cell(s RawContent) int {
+	return text_cells(string(s))
+}
+
+// text_cells measures the text that may contain ANSI escape sequences, which
+// occupy no character cells.
+func text_cells(s string) int {
+	if strings.IndexByte(s, '\x1b') < 0 {
+		return wcwidth.StringCells(s)
+	}
+	b := strings.Builder{}
+	for i := 0; i < len(s); {
+		if n := ansi_len(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		b.WriteByte(s[i])
+		i++
+	}
+	return wcwidth.StringCells(b.String())
+}
+
+func measure_lines(lines []RawContent) int {
+	w := 0
+	for _, ln := range lines {
+		if n := measure_cell(ln); n > w {
+			w = n
+		}
+	}
+	return w
+}
+
+// row_height returns the number of lines required to print the row.
+func row_height(slots []table_slot) int {
+	h := 1
+	for _, s := range slots {
+		if !s.covered && len(s.cell.lines) > h {
+			h = len(s.cell.lines)
+		}
+	}
+	return h
+}
+
+// fit_lines truncates or wraps the lines that do not fit into width.
+func fit_lines(lines []RawContent, width int, overflow Overflow) []RawContent {
+	if width <= 0 || measure_lines(lines) <= width {
+		return lines
+	}
+	r := make([]RawContent, 0, len(lines))
+	for _, ln := range lines {
+		switch {
+		case measure_cell(ln) <= width:
+			r = append(r, ln)
+		case overflow == OverflowWrap:
+			r = append(r, wrap_line(string(ln), width)...)
+		default:
+			r = append(r, truncate_line(string(ln), width))
+		}
+	}
+	return r
+}
+
+func rune_cells(r rune) int {
+	if w := wcwidth.RuneCells(r); w > 0 {
+		return w
+	}
+	return 0
+}
+
+// ansi_state tracks the escape sequences that are in effect, the text that
+// is cut within a colored run must be followed by a reset.
+type ansi_state string
+
+// skip returns the length of the escape sequence at the start of s, which is
+// added to the state, or 0 if s does not start with an escape sequence.
+func (a *ansi_state) skip(s string) int {
+	n := ansi_len(s)
+	if n > 0 {
+		if s[:n] == ansi_reset {
+			*a = ""
+		} else {
+			*a += ansi_state(s[:n])
+		}
+	}
+	return n
+}
+
+// end returns s followed by a reset when escape sequences are in effect.
+func (a ansi_state) end(s string) RawContent {
+	if a != "" {
+		s += ansi_reset
+	}
+	return RawContent(s)
+}
+
+// truncate_line cuts s to fit into width, including the trailing ellipsis.
+func truncate_line(s string, width int) RawContent {
+	const ellipsis = "…"
+	w := 0
+	esc := ansi_state("")
+	for i := 0; i < len(s); {
+		if n := esc.skip(s[i:]); n > 0 {
+			i += n
+			continue
+		}
+		r, n := utf8.DecodeRuneInString(s[i:])
+		w += rune_cells(r)
+		if w > width-1 {
+			return esc.end(s[:i] + ellipsis)
+		}
+		i += n
+	}
+	return RawContent(s)
+}
+
+// wrap_line breaks s into lines that fit into width, breaking at spaces where
+// possible. Colored runs are closed at the end of each line and continued on
+// the next one.
+func wrap_line(s string, width int) []RawContent {
+	r := []RawContent{}
+	for text_cells(s) > width {
+		w, cut, space, first := 0, 0, -1, 0
+		var esc, cut_esc, space_esc, first_esc ansi_state
+		for i := 0; i < len(s); {
+			if n := esc.skip(s[i:]); n > 0 {
+				i += n
+				continue
+			}
+			c, n := utf8.DecodeRuneInString(s[i:])
+			if first == 0 {
+				first, first_esc = i+n, esc
+			}
+			w += rune_cells(c)
+			if w > width {
+				break
+			}
+			if c == ' ' {
+				space, space_esc = i, esc
+			}
+			i += n
+			cut, cut_esc = i, esc
+		}
+		if cut < len(s) && s[cut] == ' ' {
+			space, space_esc = cut, cut_esc
+		}
+		switch {
+		case space > 0:
+			r = append(r, space_esc.end(s[:space]))
+			s = string(space_esc) + s[space+1:]
+		case cut > 0:
+			r = append(r, cut_esc.end(s[:cut]))
+			s = string(cut_esc) + s[cut:]
+		default:
+			// a single character that is wider than the column
+			r = append(r, first_esc.end(s[:first]))
+			s = string(first_esc) + s[first:]
+		}
+	}
+	return append(r, RawContent(s))
+}
+
+// span_width calculates the width of the cells that span multiple columns,
+// sep is the width of the column separator.
+func span_width(col_widths []int, col, cols, sep int) int {
+	w := sep * (cols - 1)
+	for k := col; k < col+cols && k < len(col_widths); k++ {
+		w += col_widths[k]
+	}
+	return w
+}
+
+// measure_columns calculates column widths. Single-column cells are measured
+// first, then the cells that span multiple columns distribute their excess
+// width evenly between the spanned columns.
+func measure_columns(rows [][]table_slot, sep int) []int {
+	ww := []int{}
+	grow := func(n int) {
+		for len(ww) < n {
+			ww = append(ww, 0)
+		}
+	}
+	for _, slots := range rows {
+		for _, s := range slots {
+			grow(s.col + s.cols)
+			if s.covered || s.cols > 1 {
+				continue
+			}
+			if w := measure_lines(s.cell.lines); w > ww[s.col] {
+				ww[s.col] = w
+			}
+		}
+	}
+	for _, slots := range rows {
+		for _, s := range slots {
+			if s.covered || s.cols == 1 {
+				continue
+			}
+			avail := span_width(ww, s.col, s.cols, sep)
+			need := measure_lines(s.cell.lines)
+			if need > avail {
+				extra := need - avail
+				for k := 0; k < s.cols; k++ {
+					ww[s.col+k] += extra / s.cols
+					if k < extra%s.cols {
+						ww[s.col+k]++
+					}
+				}
+			}
+		}
+	}
+	return ww
+}
+
 type table_decor struct {
 	l, c, r []byte
 }
 
-func (t table_grid) print_row(w io.Writer, cc []RawContent, decor *table_decor, col_widths []int) {
-	w.Write(decor.l)
-	for i := range cc {
-		if i > 0 {
-			w.Write(decor.c)
+// print_row writes a single line of the row, ln is the line index within the
+// multi-line cells. Trailing whitespace is trimmed.
+func print_row(w io.Writer, slots []table_slot, ln int, decor *table_decor, col_widths []int, opts *table_opts) {
+	b := bytes.Buffer{}
+	b.Write(decor.l)
+	col := 0
+	for _, s := range slots {
+		for ; col < s.col; col++ {
+			// gap
+			if col > 0 {
+				b.Write(decor.c)
+			}
+			wrepeat(&b, span_width(col_widths, col, 1, 0), nil)
 		}
-		col := 0
-		if i < len(col_widths) {
-			col = col_widths[i]
+		if col > 0 {
+			b.Write(decor.c)
 		}
-		adv := t.measure_cell(cc[i])
-		if col < adv {
-			col = adv
+		width := span_width(col_widths, s.col, s.cols, len(decor.c))
+		var content RawContent
+		if !s.covered && ln < len(s.cell.lines) {
+			content = s.cell.lines[ln]
 		}
-		w.Write(cc[i])
-		if col > adv && i+1 < len(cc) {
-			wrepeat(w, col-adv, nil)
+		adv := measure_cell(content)
+		before, after := 0, width-adv
+		if after < 0 {
+			after = 0
 		}
+		switch opts.align(s.col) {
+		case AlignRight:
+			before, after = after, 0
+		case AlignCenter:
+			before = after / 2
+			after -= before
+		}
+		wrepeat(&b, before, nil)
+		b.Write(content)
+		wrepeat(&b, after, nil)
+		col = s.col + s.cols
+	}
+	b.Write(decor.r)
+	w.Write(bytes.TrimRight(b.Bytes(), " "))
+}
+
+// print_rows writes all the lines of the row.
+func print_rows(w io.Writer, slots []table_slot, decor *table_decor, col_widths []int, opts *table_opts) {
+	h := row_height(slots)
+	for ln := 0; ln < h; ln++ {
+		if ln > 0 {
+			w.Write([]byte{'\n'})
+		}
+		print_row(w, slots, ln, decor, col_widths, opts)
 	}
-	w.Write(decor.r)
 }
 
-func (t table_grid) print_rule(w io.Writer, rule []byte, decor *table_decor, col_widths []int) {
+func print_rule(w io.Writer, rule []byte, decor *table_decor, col_widths []int) {
 	w.Write(decor.l)
 	for i := range col_widths {
 		if i > 0 {
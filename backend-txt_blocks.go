@@ -2,7 +2,6 @@ package markout
 
 import (
 	"bytes"
-	"strconv"
 	"strings"
 
 	"github.com/adnsv/go-markout/wcwidth"
@@ -10,9 +9,10 @@ import (
 
 type txt_blocks struct {
 	base_blocks
-	numbered_sections   bool
 	underlined_sections bool
 	listitem_prefix     string
+	rule_width          int
+	rule_pattern        string
 }
 
 func (bb *txt_blocks) para(s RawContent) {
@@ -20,28 +20,20 @@ func (bb *txt_blocks) para(s RawContent) {
 	bb.want_emptyln()
 }
 
-func (bb *txt_blocks) heading(counters []int, s RawContent, _ *Attrs) {
+func (bb *txt_blocks) heading(h *heading_info) {
 	if bb.enabled() {
-		level := len(counters)
+		level := h.level()
+		s := h.numbered(h.caption)
 
-		if bb.numbered_sections || (bb.underlined_sections && level <= 2) {
+		if bb.underlined_sections && level <= 2 {
 			b := bytes.Buffer{}
-			if bb.numbered_sections {
-				for _, i := range counters {
-					b.WriteString(strconv.Itoa(i))
-					b.WriteByte('.')
-				}
-				b.WriteByte(' ')
-				b.Write(s)
-			}
-			if bb.underlined_sections && level <= 2 {
-				width := wcwidth.StringCells(b.String())
-				b.WriteByte('\n')
-				if level == 1 {
-					wrepeat(&b, width, []byte("========"))
-				} else {
-					wrepeat(&b, width, []byte("--------"))
-				}
+			b.Write(s)
+			width := text_cells(b.String())
+			b.WriteByte('\n')
+			if level == 1 {
+				wrepeat(&b, width, []byte("========"))
+			} else {
+				wrepeat(&b, width, []byte("--------"))
 			}
 			s = b.Bytes()
 		}
@@ -52,6 +44,22 @@ func (bb *txt_blocks) heading(counters []int, s RawContent, _ *Attrs) {
 	bb.want_emptyln()
 }
 
+// toc writes an indented list of section headings.
+func (bb *txt_blocks) toc(entries []*heading_info, max_level int) RawContent {
+	b := bytes.Buffer{}
+	for _, h := range entries {
+		if max_level > 0 && h.level() > max_level {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		wrepeat(&b, 2*(h.level()-1), nil)
+		b.Write(h.numbered(h.caption))
+	}
+	return b.Bytes()
+}
+
 func (bb *txt_blocks) list_title(s RawContent) {
 	bb.putblock(s)
 	bb.want_nextln()
@@ -71,7 +79,7 @@ func (bb *txt_blocks) list_level_done(counters []int, to_broad bool) {
 	}
 }
 
-func (bb *txt_blocks) list_item(counters []int, broad bool, s ...RawContent) {
+func (bb *txt_blocks) list_item(counters []int, broad bool, task list_task, s ...RawContent) {
 	if bb.enabled() {
 		level := len(counters)
 		counter := counters[level-1]
@@ -81,22 +89,23 @@ func (bb *txt_blocks) list_item(counters []int, broad bool, s ...RawContent) {
 			ln = s[0]
 		}
 
-		var ind int
+		var prefix string
 		if counter < 0 {
 			// unordered
-			bb.putblock_ex(level-1, bb.listitem_prefix, ln, "")
-			ind = len(bb.listitem_prefix)
+			prefix = bb.listitem_prefix
 		} else {
 			// ordered
-			num := strconv.FormatInt(int64(counter), 10) + ". "
-			bb.putblock_ex(level-1, num, ln, "")
-			ind = len(num)
+			prefix = bb.list_marker(level, counter)
 		}
+		prefix += task.marker()
+		ind_str := strings.Repeat(" ", wcwidth.StringCells(prefix))
+		// line breaks continue at the item content indentation
+		lead := strings.Repeat("  ", level-1) + ind_str
+		bb.putblock_ex(level-1, prefix, indent_lines(ln, lead), "")
 		if len(s) > 1 {
-			ind_str := strings.Repeat(" ", ind)
 			for _, ln = range s[1:] {
 				bb.want_emptyln()
-				bb.putblock_ex(level-1, ind_str, ln, "")
+				bb.putblock_ex(level-1, ind_str, indent_lines(ln, lead), "")
 			}
 		}
 	}
@@ -107,26 +116,84 @@ func (bb *txt_blocks) list_item(counters []int, broad bool, s ...RawContent) {
 	}
 }
 
+func (bb *txt_blocks) table_row(cells ...table_cell) {
+	bb.base_blocks.table_row(cells...)
+	if bb.table_opts.streaming {
+		bb.flush_table(false)
+	}
+}
+
 func (bb *txt_blocks) end_table() {
-	if len(bb.table) > 1 {
-		cols := []int{}
-		for r := range bb.table {
-			bb.table.measure_cells(bb.table[r], &cols)
+	if bb.table_stream.written > 0 || len(bb.table) > 1 && !bb.defer_table(bb.flush_table) {
+		bb.flush_table(true)
+	}
+	bb.table = bb.table[:0]
+	bb.want_emptyln()
+}
+
+func (bb *txt_blocks) flush_table(final bool) {
+	decor := table_decor{nil, []byte{' '}, nil}
+	opts := &bb.table_opts
+	st := &bb.table_stream
+	st.add(bb.table, bb.cell_lines, false)
+	if !st.ready(opts, len(decor.c), final) {
+		return
+	}
+	eol := []byte{'\n'}
+	rule := []byte("--------")
+	for _, row := range st.take() {
+		for _, s := range row {
+			if !s.covered {
+				width := span_width(st.widths, s.col, s.cols, len(decor.c))
+				s.cell.lines = fit_lines(s.cell.lines, width, opts.overflow)
+			}
 		}
-		eol := []byte{'\n'}
-		decor := table_decor{nil, []byte{' '}, nil}
-		rule := []byte("--------")
-		bb.do_nextline()
-		bb.table.print_row(bb.out, bb.table[0], &decor, cols)
-		bb.out.Write(eol)
-		bb.table.print_rule(bb.out, rule, &decor, cols)
-		for _, row := range bb.table[1:] {
+		if st.written == 0 {
+			if len(opts.caption) > 0 {
+				bb.putblock(opts.caption)
+				bb.want_nextln()
+			}
+			bb.do_nextline()
+			print_rows(bb.out, row, &decor, st.widths, opts)
 			bb.out.Write(eol)
-			bb.table.print_row(bb.out, row, &decor, cols)
+			print_rule(bb.out, rule, &decor, st.widths)
+		} else {
+			bb.out.Write(eol)
+			print_rows(bb.out, row, &decor, st.widths, opts)
 		}
+		st.written++
 	}
-	bb.table = bb.table[:0]
-	bb.want_emptyln()
+	bb.table_flushed()
+}
+
+// cell_lines lays out table cell blocks as a multi-line box, paragraphs are
+// separated with empty lines, list items are prefixed with markers.
+func (bb *txt_blocks) cell_lines(c *table_cell) []RawContent {
+	r := []RawContent{}
+	for i, b := range c.blocks {
+		if i > 0 && !(b.is_item() && c.blocks[i-1].is_item()) {
+			r = append(r, nil)
+		}
+		prefix := ""
+		if b.is_item() {
+			prefix = strings.Repeat("  ", b.level-1)
+			if b.counter < 0 {
+				prefix += bb.listitem_prefix
+			} else {
+				prefix += b.style.Format(b.counter) + ". "
+			}
+			prefix += b.task.marker()
+		}
+		ind := strings.Repeat(" ", wcwidth.StringCells(prefix))
+		for k, ln := range bytes.Split(b.content, []byte{'\n'}) {
+			if k == 0 {
+				r = append(r, append([]byte(prefix), ln...))
+			} else {
+				r = append(r, append([]byte(ind), ln...))
+			}
+		}
+	}
+	return r
 }
 
 func (bb *txt_blocks) codeblock(lang string, s RawContent) {
@@ -135,3 +202,52 @@ func (bb *txt_blocks) codeblock(lang string, s RawContent) {
 	bb.out.Write(s)
 	bb.want_emptyln()
 }
+
+// thematic_break writes a line filled with the rule pattern.
+func (bb *txt_blocks) thematic_break() {
+	b := bytes.Buffer{}
+	pattern := []rune(bb.rule_pattern)
+	for i, w := 0, 0; i < bb.rule_width*len(pattern); i++ {
+		r := pattern[i%len(pattern)]
+		w += rune_cells(r)
+		if w > bb.rule_width {
+			break
+		}
+		b.WriteRune(r)
+	}
+	bb.putblock(RawContent(b.Bytes()))
+	bb.want_emptyln()
+}
+
+// page_break writes a form feed character.
+func (bb *txt_blocks) page_break() {
+	bb.putblock(RawContent("\f"))
+	bb.want_nextln()
+}
+
+// math_block writes the formula source indented as a block.
+func (bb *txt_blocks) math_block(tex string) error {
+	bb.indented_block(tex)
+	return nil
+}
+
+// diagram writes the diagram source indented as a block.
+func (bb *txt_blocks) diagram(kind, source string) error {
+	bb.indented_block(source)
+	return nil
+}
+
+func (bb *txt_blocks) indented_block(s string) {
+	b := bytes.Buffer{}
+	for i, ln := range strings.Split(source_lines(s), "\n") {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		if ln != "" {
+			b.WriteString("    ")
+			b.WriteString(ln)
+		}
+	}
+	bb.putblock(b.Bytes())
+	bb.want_emptyln()
+}
@@ -2,19 +2,72 @@ package markout
 
 import (
 	"bytes"
+	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/exp/slices"
 )
 
 type txt_inlines struct {
 	base_inlines
-	pending_link *RawContent
+	pending_link  *RawContent
+	style_markers map[Style][2]string
+	combining     bool  // use combining characters for strikethrough and underline
+	style_offsets []int // buffer offsets where styled spans begin
+	ansi_colors   bool
+}
+
+// txt_default_style_markers are written around styled spans, quotes are
+// configured separately with quotation marks.
+var txt_default_style_markers = map[Style][2]string{
+	StrongStyle:        {"**", "**"},
+	EmphasizedStyle:    {"*", "*"},
+	StrikethroughStyle: {"~~", "~~"},
+	UnderlineStyle:     {"_", "_"},
+	HighlightStyle:     {"==", "=="},
+	SubscriptStyle:     {"~", "~"}, // as in pandoc, `_` opens underlined spans
+	SuperscriptStyle:   {"^", ""},
+}
+
+// setup_style_markers combines the default style markers with overrides.
+func (ii *txt_inlines) setup_style_markers(overrides map[Style][2]string) {
+	ii.style_markers = map[Style][2]string{}
+	for sty, m := range txt_default_style_markers {
+		ii.style_markers[sty] = m
+	}
+	for sty, m := range overrides {
+		ii.style_markers[sty] = m
+	}
+}
+
+func (ii *txt_inlines) markers(sty Style) [2]string {
+	if ii.style_markers == nil {
+		return txt_default_style_markers[sty]
+	}
+	return ii.style_markers[sty]
+}
+
+// combining_mark returns the combining character used for the style, or 0.
+func (ii *txt_inlines) combining_mark(sty Style) rune {
+	if ii.combining {
+		switch sty {
+		case StrikethroughStyle:
+			return '\u0336'
+		case UnderlineStyle:
+			return '\u0332'
+		}
+	}
+	return 0
 }
 
 func txt_scramble(s string) RawContent {
 	return RawContent(s)
 }
 
+func (ii *txt_inlines) format() string {
+	return FormatTXT
+}
+
 func (ii *txt_inlines) current_mode() imode {
 	return pick(ii.pending_link != nil, iflow, iflow|ilink)
 }
@@ -47,7 +100,7 @@ func (ii *txt_inlines) put_raw(b *bytes.Buffer, s RawContent) {
 	b.Write(s)
 }
 func (ii *txt_inlines) put_str(b *bytes.Buffer, s string) {
-	b.Write(txt_scramble(s))
+	b.Write(txt_scramble(ii.smarten(b, s)))
 }
 func (ii *txt_inlines) code_raw(b *bytes.Buffer, s RawContent) {
 	b.WriteByte('`')
@@ -62,30 +115,57 @@ func (ii *txt_inlines) code_str(b *bytes.Buffer, s string) {
 func (ii *txt_inlines) codeblock_line(b *bytes.Buffer, s string) {
 	b.Write([]byte(s))
 }
+func (ii *txt_inlines) line_break(b *bytes.Buffer) {
+	b.WriteByte('\n')
+}
+func (ii *txt_inlines) math(b *bytes.Buffer, tex string) {
+	ii.code_str(b, tex)
+}
 func (ii *txt_inlines) begin_styled(b *bytes.Buffer, sty Style) {
 	ii.start_styled(sty)
+	ii.style_offsets = append(ii.style_offsets, b.Len())
 	switch sty {
 	case SingleQuotedStyle:
 		b.WriteString(ii.quote_specs[0])
 	case DoubleQuotedStyle:
 		b.WriteString(ii.quote_specs[2])
-	case StrongStyle:
-		b.WriteString("**")
-	case EmphasizedStyle:
-		b.WriteString("*")
+	default:
+		if ii.combining_mark(sty) == 0 {
+			b.WriteString(ii.markers(sty)[0])
+		}
 	}
 }
 func (ii *txt_inlines) end_styled(b *bytes.Buffer) {
 	sty := ii.finish_styled()
+	n := len(ii.style_offsets) - 1
+	offset := ii.style_offsets[n]
+	ii.style_offsets = ii.style_offsets[:n]
 	switch sty {
 	case SingleQuotedStyle:
 		b.WriteString(ii.quote_specs[1])
 	case DoubleQuotedStyle:
 		b.WriteString(ii.quote_specs[3])
-	case StrongStyle:
-		b.WriteString("**")
-	case EmphasizedStyle:
-		b.WriteString("*")
+	default:
+		if mark := ii.combining_mark(sty); mark != 0 && offset <= b.Len() {
+			s := slices.Clone(b.Bytes()[offset:])
+			b.Truncate(offset)
+			for i := 0; i < len(s); {
+				// escape sequences and placeholders are not text
+				if n := ansi_len(s[i:]) + placeholder_len(s[i:]); n > 0 {
+					b.Write(s[i : i+n])
+					i += n
+					continue
+				}
+				r, n := utf8.DecodeRune(s[i:])
+				b.Write(s[i : i+n])
+				if unicode.IsPrint(r) && !unicode.IsSpace(r) {
+					b.WriteRune(mark)
+				}
+				i += n
+			}
+		} else {
+			b.WriteString(ii.markers(sty)[1])
+		}
 	}
 }
 func (ii *txt_inlines) begin_link(b *bytes.Buffer, url RawContent) {
@@ -99,7 +179,7 @@ func (ii *txt_inlines) end_link(b *bytes.Buffer) {
 	ii.pending_link = nil
 }
 
-func (ii *txt_inlines) simple_link(b *bytes.Buffer, caption RawContent, url RawContent) {
+func (ii *txt_inlines) simple_link(b *bytes.Buffer, caption RawContent, url RawContent, la *LinkAttrs) {
 	if len(caption) == 0 || slices.Equal(caption, url) {
 		b.Write(url)
 	} else {
@@ -110,3 +190,37 @@ func (ii *txt_inlines) simple_link(b *bytes.Buffer, caption RawContent, url RawC
 		b.WriteByte(')')
 	}
 }
+
+func (ii *txt_inlines) anchor_link(b *bytes.Buffer, caption RawContent, id string) {
+	b.Write(caption)
+}
+
+func (ii *txt_inlines) attr_span(b *bytes.Buffer, aa *Attrs, content RawContent) {
+	b.Write(content)
+}
+
+func (ii *txt_inlines) colored(b *bytes.Buffer, c Color, content RawContent) {
+	if esc := c.ansi(); ii.ansi_colors && esc != "" {
+		b.WriteString(esc)
+		b.Write(content)
+		b.WriteString(ansi_reset)
+	} else {
+		b.Write(content)
+	}
+}
+
+func (ii *txt_inlines) badge(b *bytes.Buffer, v *badge_wrapper) {
+	content := bytes.Buffer{}
+	content.WriteByte('[')
+	ii.put_str(&content, v.caption())
+	content.WriteByte(']')
+	ii.colored(b, v.color, content.Bytes())
+}
+
+// term expands the term on its first use: `Term (definition)`.
+func (ii *txt_inlines) term(b *bytes.Buffer, term, definition string, first bool) {
+	ii.put_str(b, term)
+	if first && definition != "" {
+		ii.put_str(b, " ("+definition+")")
+	}
+}
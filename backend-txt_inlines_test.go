@@ -0,0 +1,29 @@
+package markout
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_txt_combining(t *testing.T) {
+	b := bytes.Buffer{}
+	w := NewTXT(&b, TXTOptions{CombiningStyles: true})
+	w.Para(Strikethrough(Ref("later", RefCaption)))
+	w.BeginAttrSection(Attrs{Identifier: "later"}, "Later")
+	w.EndSection()
+	w.Close()
+	if got, want := b.String(), "Later\n\nLater\n\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if err := w.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	b.Reset()
+	w = NewTXT(&b, TXTOptions{CombiningStyles: true, ANSIColors: true})
+	w.Para(Strikethrough(Colored(Red, "a b")))
+	w.Close()
+	if got, want := b.String(), "\x1b[31ma̶ b̶\x1b[0m\n\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,136 @@
+package markout
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Color is a color from the named palette, optionally overridden with an RGB
+// value. Colors that are not in the palette can be specified with RGB values
+// only, such colors are not displayed in plain text output.
+type Color struct {
+	Name string // palette color: red, green, yellow, blue, magenta, cyan, gray
+	Hex  string // optional RGB override: #1e90ff
+}
+
+// The named palette.
+var (
+	Red     = Color{Name: "red"}
+	Green   = Color{Name: "green"}
+	Yellow  = Color{Name: "yellow"}
+	Blue    = Color{Name: "blue"}
+	Magenta = Color{Name: "magenta"}
+	Cyan    = Color{Name: "cyan"}
+	Gray    = Color{Name: "gray"}
+)
+
+// HexColor creates a color from an RGB value, such as "#1e90ff".
+func HexColor(hex string) Color {
+	return Color{Hex: hex}
+}
+
+type palette_entry struct {
+	hex  string
+	ansi string // SGR foreground code
+}
+
+var palette = map[string]palette_entry{
+	"red":     {"#d73a49", "31"},
+	"green":   {"#28a745", "32"},
+	"yellow":  {"#dbab09", "33"},
+	"blue":    {"#0366d6", "34"},
+	"magenta": {"#8a63d2", "35"},
+	"cyan":    {"#1b9aaa", "36"},
+	"gray":    {"#6a737d", "90"},
+}
+
+// css returns the RGB value used in HTML output.
+func (c Color) css() string {
+	if c.Hex != "" {
+		return c.Hex
+	}
+	if e, ok := palette[c.Name]; ok {
+		return e.hex
+	}
+	return c.Name
+}
+
+// ansi returns the escape sequence that starts colored output in terminals,
+// or an empty string for colors outside of the palette.
+func (c Color) ansi() string {
+	if e, ok := palette[c.Name]; ok {
+		return "\x1b[" + e.ansi + "m"
+	}
+	return ""
+}
+
+const ansi_reset = "\x1b[0m"
+
+// ansi_len returns the length of the escape sequence at the start of s, or 0
+// if s does not start with an escape sequence.
+func ansi_len[T string | []byte](s T) int {
+	if len(s) < 2 || s[0] != '\x1b' || s[1] != '[' {
+		return 0
+	}
+	i := 2
+	for i < len(s) && (s[i] < 0x40 || s[i] > 0x7e) {
+		i++
+	}
+	if i == len(s) {
+		return i
+	}
+	return i + 1
+}
+
+// Colored creates a wrapper for colored inline spans.
+func Colored(c Color, a any) colored_span {
+	return colored_span{color: c, content: a}
+}
+
+// Badge creates a wrapper for status indicators, such as "build: passing".
+// The label is optional.
+func Badge(label, value string, c Color) badge_wrapper {
+	return badge_wrapper{label: label, value: value, color: c}
+}
+
+type colored_span struct {
+	color   Color
+	content any
+}
+
+type badge_wrapper struct {
+	label, value string
+	color        Color
+}
+
+// caption returns the text of the badge: `label: value`
+func (v *badge_wrapper) caption() string {
+	if v.label == "" {
+		return v.value
+	}
+	return v.label + ": " + v.value
+}
+
+// shields_url returns the URL of the badge image rendered by shields.io.
+func (v *badge_wrapper) shields_url() string {
+	esc := strings.NewReplacer("-", "--", "_", "__", " ", "_")
+	color := v.color.Name
+	if v.color.Hex != "" || color == "" {
+		color = strings.TrimPrefix(v.color.css(), "#")
+	} else if color == "gray" {
+		color = "lightgrey"
+	}
+	path := url.PathEscape(esc.Replace(v.value)) + "-" + url.PathEscape(color)
+	if v.label != "" {
+		path = url.PathEscape(esc.Replace(v.label)) + "-" + path
+	}
+	return "https://img.shields.io/badge/" + path
+}
+
+// MDBadges specifies how badges are written in markdown.
+type MDBadges int
+
+const (
+	MDBadgesText    = MDBadges(iota) // plain text: [label: value]
+	MDBadgesShields                  // images rendered by shields.io
+)
@@ -0,0 +1,108 @@
+package markout
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func Test_badge_shields_url(t *testing.T) {
+	tests := []struct {
+		v    badge_wrapper
+		want string
+	}{
+		{badge_wrapper{"build", "passing", Green}, "https://img.shields.io/badge/build-passing-green"},
+		{badge_wrapper{"", "PASS", Gray}, "https://img.shields.io/badge/PASS-lightgrey"},
+		{badge_wrapper{"go-version", "1.19 beta_1", HexColor("#1e90ff")}, "https://img.shields.io/badge/go--version-1.19_beta__1-1e90ff"},
+	}
+	for _, tt := range tests {
+		if got := tt.v.shields_url(); got != tt.want {
+			t.Errorf("shields_url() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func Test_text_cells(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"PASS", 4},
+		{"\x1b[32m[PASS]\x1b[0m", 6},
+		{"\x1b[1;31mé\x1b[0m", 1},
+	}
+	for _, tt := range tests {
+		if got := text_cells(tt.s); got != tt.want {
+			t.Errorf("text_cells(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func Test_fit_lines_colored(t *testing.T) {
+	red := func(s string) string { return "\x1b[31m" + s + ansi_reset }
+	if got, want := string(truncate_line(red("failed badly"), 6)), "\x1b[31mfaile…"+ansi_reset; got != want {
+		t.Errorf("truncate_line() = %q, want %q", got, want)
+	}
+	if got, want := string(truncate_line(red("ok")+" and more", 6)), red("ok")+" an…"; got != want {
+		t.Errorf("truncate_line() = %q, want %q", got, want)
+	}
+	got := wrap_line(red("failed badly"), 6)
+	want := []RawContent{RawContent(red("failed")), RawContent(red("badly"))}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrap_line() = %q, want %q", got, want)
+	}
+}
+
+func Test_streaming_table_colored(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := NewTXT(&buf, TXTOptions{ANSIColors: true})
+	w.BeginTableEx(TableSpec{
+		Columns:   []TableColumn{{Header: "Status", Width: 6}},
+		Streaming: true,
+	})
+	w.TableRow(Colored(Red, "failed badly"))
+	w.Close()
+	want := "Status\n------\n\x1b[31mfaile…\x1b[0m\n\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func Test_colored_heading_underline(t *testing.T) {
+	buf := bytes.Buffer{}
+	w := NewTXT(&buf, TXTOptions{ANSIColors: true, UnderlinedSections: true})
+	w.BeginSection(Badge("", "PASS", Green))
+	w.EndSection()
+	w.Close()
+	want := "\x1b[32m[PASS]\x1b[0m\n======\n\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func ExampleBadge() {
+	write_each(func(w Writer) {
+		w.Paraf("Status: %s %s", Badge("build", "passing", Green), Colored(Red, "2 warnings"))
+	}, TXTOptions{}, MDOptions{}, MDOptions{Badges: MDBadgesShields}, HTMLOptions{})
+
+	buf := bytes.Buffer{}
+	w := NewTXT(&buf, TXTOptions{ANSIColors: true})
+	w.Paraf("Status: %s", Badge("", "PASS", Green))
+	w.Close()
+	fmt.Printf("%q\n", buf.String())
+	// Output:
+	// Status: [build: passing] 2 warnings
+	//
+	// Status: \[build: passing\] 2 warnings
+	//
+	// Status: ![build: passing](https://img.shields.io/badge/build-passing-green) 2 warnings
+	//
+	// <html>
+	// <body>
+	// <p>Status: <span class="badge" style="background-color: #28a745; color: #fff">build: passing</span> <span style="color: #d73a49">2 warnings</span></p>
+	//
+	// </body>
+	// </html>
+	// "Status: \x1b[32m[PASS]\x1b[0m\n\n"
+}
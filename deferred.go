@@ -0,0 +1,113 @@
+package markout
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"strconv"
+)
+
+// deferred_output buffers the output that contains placeholders. Placeholders
+// are resolved when the output is flushed, which allows writing content that
+// depends on the parts of the document that are not written yet.
+type deferred_output struct {
+	out       io.Writer
+	buf       bytes.Buffer
+	resolvers []func() RawContent
+	prefix    []byte // starts placeholders, unique for each output
+}
+
+// placeholder markers are `\x00<nonce>:<index>\x00`, the random nonce keeps
+// user content that contains NUL characters from being mistaken for
+// placeholders.
+const (
+	placeholder_end       = '\x00'
+	placeholder_nonce_len = 8 // random bytes, hex encoded in markers
+)
+
+func (d *deferred_output) Write(p []byte) (int, error) {
+	return d.buf.Write(p)
+}
+
+// placeholder returns a marker that is replaced with the result of resolve
+// when the output is flushed.
+func (d *deferred_output) placeholder(resolve func() RawContent) RawContent {
+	if d.prefix == nil {
+		nonce := make([]byte, placeholder_nonce_len)
+		rand.Read(nonce)
+		d.prefix = append([]byte{placeholder_end}, hex.EncodeToString(nonce)+":"...)
+	}
+	d.resolvers = append(d.resolvers, resolve)
+	m := append([]byte{}, d.prefix...)
+	m = strconv.AppendInt(m, int64(len(d.resolvers)-1), 10)
+	return append(m, placeholder_end)
+}
+
+// placeholder_len returns the length of the placeholder marker at the start
+// of s, or 0 if s does not start with a marker.
+func placeholder_len(s []byte) int {
+	n := 1 + 2*placeholder_nonce_len
+	if len(s) < n+3 || s[0] != placeholder_end || s[n] != ':' {
+		return 0
+	}
+	for _, c := range s[1:n] {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return 0
+		}
+	}
+	i := n + 1
+	for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+		i++
+	}
+	if i == n+1 || i == len(s) || s[i] != placeholder_end {
+		return 0
+	}
+	return i + 1
+}
+
+// has_placeholders returns true if s may contain placeholder markers.
+func (d *deferred_output) has_placeholders(s []byte) bool {
+	return d.prefix != nil && bytes.Contains(s, d.prefix)
+}
+
+// resolve returns s with the placeholders replaced by the resolved content.
+func (d *deferred_output) resolve(s RawContent) RawContent {
+	if !d.has_placeholders(s) {
+		return s
+	}
+	b := bytes.Buffer{}
+	d.expand(&b, s)
+	return b.Bytes()
+}
+
+// flush resolves placeholders and writes buffered content to the underlying
+// writer.
+func (d *deferred_output) flush() {
+	d.expand(d.out, d.buf.Bytes())
+	d.buf.Reset()
+	d.resolvers = nil
+}
+
+// expand writes s into w, replacing placeholders with the resolved content.
+func (d *deferred_output) expand(w io.Writer, s []byte) {
+	for d.prefix != nil {
+		i := bytes.Index(s, d.prefix)
+		if i < 0 {
+			break
+		}
+		tail := s[i+len(d.prefix):]
+		n := bytes.IndexByte(tail, placeholder_end)
+		k, err := strconv.Atoi(string(tail[:pick(n < 0, n, 0)]))
+		if n < 0 || err != nil || k >= len(d.resolvers) {
+			// not a placeholder, write as-is
+			w.Write(s[:i+len(d.prefix)])
+			s = tail
+			continue
+		}
+		w.Write(s[:i])
+		w.Write(d.resolvers[k]())
+		s = tail[n+1:]
+	}
+	w.Write(s)
+}
@@ -0,0 +1,49 @@
+package markout
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_deferred_output_flush(t *testing.T) {
+	out := bytes.Buffer{}
+	d := &deferred_output{out: &out}
+	d.Write([]byte("a\x001\x00b "))
+	d.Write(d.placeholder(func() RawContent { return RawContent("X") }))
+	d.Write([]byte(" c\x00"))
+	d.Write(d.placeholder(func() RawContent { return RawContent("Y") }))
+	d.flush()
+	if got, want := out.String(), "a\x001\x00b X c\x00Y"; got != want {
+		t.Errorf("flush() = %q, want %q", got, want)
+	}
+}
+
+func Test_deferred_table(t *testing.T) {
+	write := func(w Writer) {
+		w.BeginTable("Section", "N")
+		w.TableRow(Ref("later-heading", RefCaption), "1")
+		w.EndTable()
+		w.BeginSection("Later Heading")
+		w.EndSection()
+		w.Close()
+	}
+	tests := []struct {
+		name string
+		w    func(b *bytes.Buffer) Writer
+		want string
+	}{
+		{"txt", func(b *bytes.Buffer) Writer { return NewTXT(b, TXTOptions{}) },
+			"Section       N\n------------- -\nLater Heading 1\n\nLater Heading\n\n"},
+		{"md", func(b *bytes.Buffer) Writer { return NewMD(b, MDOptions{}) },
+			"| Section                         | N\n|---------------------------------|---\n| [Later Heading](#later-heading) | 1\n\n# Later Heading\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := bytes.Buffer{}
+			write(tt.w(&b))
+			if got := b.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package markout
+
+import "fmt"
+
+// Diagram kinds supported by the common diagramming tools.
+const (
+	MermaidDiagram  = "mermaid"
+	GraphvizDiagram = "dot"
+	PlantUMLDiagram = "plantuml"
+)
+
+// DiagramRenderer converts diagram sources to HTML content, typically inline
+// SVG. Returning nil content without an error writes the diagram source
+// instead, which allows renderers to support only some diagram kinds.
+type DiagramRenderer interface {
+	RenderDiagram(kind, source string) (RawContent, error)
+}
+
+// DiagramRendererFunc is an adapter that allows ordinary functions to be used
+// as diagram renderers.
+type DiagramRendererFunc func(kind, source string) (RawContent, error)
+
+func (f DiagramRendererFunc) RenderDiagram(kind, source string) (RawContent, error) {
+	return f(kind, source)
+}
+
+func diagram_error(kind string, err error) error {
+	return fmt.Errorf("markout: rendering %s diagram: %w", kind, err)
+}
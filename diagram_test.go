@@ -0,0 +1,54 @@
+package markout
+
+func ExampleWriter_Diagram() {
+	const source = "graph LR\n  A --> B"
+	html := HTMLOptions{
+		Scripts: []string{"https://cdn.jsdelivr.net/npm/mermaid/dist/mermaid.min.js"},
+		DiagramRenderer: DiagramRendererFunc(func(kind, source string) (RawContent, error) {
+			if kind != GraphvizDiagram {
+				return nil, nil
+			}
+			return RawContent(`<svg class="graphviz"></svg>`), nil
+		}),
+	}
+	write_each(func(w Writer) {
+		w.Para("Flow:")
+		w.Diagram(MermaidDiagram, source)
+		w.Diagram(GraphvizDiagram, "digraph { a -> b }")
+	}, TXTOptions{}, MDOptions{}, html)
+	// Output:
+	// Flow:
+	//
+	//     graph LR
+	//       A --> B
+	//
+	//     digraph { a -> b }
+	//
+	// Flow:
+	//
+	// ```mermaid
+	// graph LR
+	//   A --> B
+	// ```
+	//
+	// ```dot
+	// digraph { a -> b }
+	// ```
+	//
+	// <html>
+	// <head>
+	//   <script src="https://cdn.jsdelivr.net/npm/mermaid/dist/mermaid.min.js"></script>
+	// </head>
+	// <body>
+	// <p>Flow:</p>
+	//
+	// <pre class="mermaid">
+	// graph LR
+	//   A --&gt; B
+	// </pre>
+	//
+	// <svg class="graphviz"></svg>
+	//
+	// </body>
+	// </html>
+}
@@ -0,0 +1,65 @@
+package markout
+
+import (
+	"strconv"
+	"strings"
+)
+
+// document holds the state that is shared between block-level and inline
+// formatting of a single writer.
+type document struct {
+	headings     heading_registry
+	terms        term_registry
+	defer_output func() *deferred_output
+	unresolved   []string // ids of the references that could not be resolved
+	failures     []error  // errors reported by custom renderers
+}
+
+// RefError is reported for the cross-references that can not be resolved when
+// the writer is closed.
+type RefError struct {
+	IDs []string
+}
+
+func (e *RefError) Error() string {
+	return "markout: unresolved references: " + strings.Join(e.IDs, ", ")
+}
+
+// TermError is reported for the terms that are used without a definition.
+type TermError struct {
+	Terms []string
+}
+
+func (e *TermError) Error() string {
+	return "markout: undefined terms: " + strings.Join(e.Terms, ", ")
+}
+
+func (d *document) err() error {
+	if len(d.unresolved) > 0 {
+		return &RefError{IDs: d.unresolved}
+	}
+	if len(d.terms.undefined) > 0 {
+		return &TermError{Terms: d.terms.undefined}
+	}
+	if len(d.failures) > 0 {
+		return d.failures[0]
+	}
+	return nil
+}
+
+// ref_caption formats the text of a cross-reference to heading h.
+func ref_caption(h *heading_info, sty RefStyle) RawContent {
+	switch sty {
+	case RefNumber:
+		if h.number != "" {
+			return RawContent(pick(h.appendix, "Section ", "Appendix ") + h.number)
+		}
+		ss := make([]string, len(h.counters))
+		for i, c := range h.counters {
+			ss[i] = strconv.Itoa(c)
+		}
+		return RawContent("Section " + strings.Join(ss, "."))
+	default:
+		return h.linkable
+	}
+}
@@ -0,0 +1,46 @@
+package markout
+
+// Emoji specifies the handling of emoji shortcodes, such as
+// `:white_check_mark:`, in plain strings.
+type Emoji int
+
+const (
+	EmojiNone       = Emoji(iota) // no special handling, shortcodes are written as regular text
+	EmojiUnicode                  // known shortcodes are replaced with Unicode emoji
+	EmojiShortcodes               // known shortcodes are written as-is, for the platforms that render them
+)
+
+// emoji_match is a known shortcode detected in plain text.
+type emoji_match struct {
+	start, end int    // byte range of the shortcode, including colons
+	emoji      string // Unicode replacement
+}
+
+// find_emoji detects known `:shortcode:` sequences in s.
+func find_emoji(s string) []emoji_match {
+	var r []emoji_match
+	for i := 0; i < len(s); i++ {
+		if s[i] != ':' {
+			continue
+		}
+		j := i + 1
+		for j < len(s) && is_shortcode_char(s[j]) {
+			j++
+		}
+		if j == i+1 || j == len(s) || s[j] != ':' {
+			i = j - 1
+			continue
+		}
+		if e, ok := emoji_shortcodes[s[i+1:j]]; ok {
+			r = append(r, emoji_match{start: i, end: j + 1, emoji: e})
+			i = j
+		} else {
+			i = j - 1 // the closing colon may start another shortcode
+		}
+	}
+	return r
+}
+
+func is_shortcode_char(c byte) bool {
+	return is_alnum(c) || c == '_' || c == '+' || c == '-'
+}
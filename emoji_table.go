@@ -0,0 +1,191 @@
+package markout
+
+// emoji_shortcodes maps the commonly used GitHub/Slack shortcodes (without
+// colons) to Unicode emoji.
+var emoji_shortcodes = map[string]string{
+	"+1":                          "\U0001f44d",
+	"-1":                          "\U0001f44e",
+	"100":                         "\U0001f4af",
+	"1st_place_medal":             "\U0001f947",
+	"alarm_clock":                 "\u23f0",
+	"alien":                       "\U0001f47d",
+	"ambulance":                   "\U0001f691",
+	"angry":                       "\U0001f620",
+	"arrow_down":                  "\u2b07\ufe0f",
+	"arrow_left":                  "\u2b05\ufe0f",
+	"arrow_right":                 "\u27a1\ufe0f",
+	"arrow_up":                    "\u2b06\ufe0f",
+	"arrows_counterclockwise":     "\U0001f504",
+	"art":                         "\U0001f3a8",
+	"ballot_box_with_check":       "\u2611\ufe0f",
+	"bangbang":                    "\u203c\ufe0f",
+	"bar_chart":                   "\U0001f4ca",
+	"beer":                        "\U0001f37a",
+	"beetle":                      "\U0001fab2",
+	"bell":                        "\U0001f514",
+	"black_circle":                "\u26ab",
+	"blue_circle":                 "\U0001f535",
+	"blue_heart":                  "\U0001f499",
+	"blush":                       "\U0001f60a",
+	"book":                        "\U0001f4d6",
+	"bookmark":                    "\U0001f516",
+	"books":                       "\U0001f4da",
+	"boom":                        "\U0001f4a5",
+	"broken_heart":                "\U0001f494",
+	"bug":                         "\U0001f41b",
+	"bulb":                        "\U0001f4a1",
+	"cake":                        "\U0001f370",
+	"calendar":                    "\U0001f4c6",
+	"cat":                         "\U0001f431",
+	"cd":                          "\U0001f4bf",
+	"chart_with_downwards_trend":  "\U0001f4c9",
+	"chart_with_upwards_trend":    "\U0001f4c8",
+	"checkered_flag":              "\U0001f3c1",
+	"clap":                        "\U0001f44f",
+	"clipboard":                   "\U0001f4cb",
+	"cloud":                       "\u2601\ufe0f",
+	"coffee":                      "\u2615",
+	"collision":                   "\U0001f4a5",
+	"computer":                    "\U0001f4bb",
+	"confetti_ball":               "\U0001f38a",
+	"confused":                    "\U0001f615",
+	"construction":                "\U0001f6a7",
+	"cool":                        "\U0001f192",
+	"cry":                         "\U0001f622",
+	"date":                        "\U0001f4c5",
+	"desktop_computer":            "\U0001f5a5\ufe0f",
+	"dog":                         "\U0001f436",
+	"email":                       "\U0001f4e7",
+	"envelope":                    "\u2709\ufe0f",
+	"evergreen_tree":              "\U0001f332",
+	"exclamation":                 "\u2757",
+	"eyes":                        "\U0001f440",
+	"file_folder":                 "\U0001f4c1",
+	"fire":                        "\U0001f525",
+	"floppy_disk":                 "\U0001f4be",
+	"free":                        "\U0001f193",
+	"gear":                        "\u2699\ufe0f",
+	"ghost":                       "\U0001f47b",
+	"globe_with_meridians":        "\U0001f310",
+	"green_circle":                "\U0001f7e2",
+	"green_heart":                 "\U0001f49a",
+	"grey_exclamation":            "\u2755",
+	"grey_question":               "\u2754",
+	"grin":                        "\U0001f601",
+	"hammer":                      "\U0001f528",
+	"hammer_and_wrench":           "\U0001f6e0\ufe0f",
+	"heart":                       "\u2764\ufe0f",
+	"heart_eyes":                  "\U0001f60d",
+	"heavy_check_mark":            "\u2714\ufe0f",
+	"heavy_dollar_sign":           "\U0001f4b2",
+	"heavy_minus_sign":            "\u2796",
+	"heavy_multiplication_x":      "\u2716\ufe0f",
+	"heavy_plus_sign":             "\u2795",
+	"hourglass":                   "\u231b",
+	"hourglass_flowing_sand":      "\u23f3",
+	"inbox_tray":                  "\U0001f4e5",
+	"information_source":          "\u2139\ufe0f",
+	"joy":                         "\U0001f602",
+	"key":                         "\U0001f511",
+	"keyboard":                    "\u2328\ufe0f",
+	"large_blue_circle":           "\U0001f535",
+	"large_blue_diamond":          "\U0001f537",
+	"large_orange_diamond":        "\U0001f536",
+	"laughing":                    "\U0001f606",
+	"link":                        "\U0001f517",
+	"lipstick":                    "\U0001f484",
+	"lock":                        "\U0001f512",
+	"loudspeaker":                 "\U0001f4e2",
+	"mag":                         "\U0001f50d",
+	"mag_right":                   "\U0001f50e",
+	"man_technologist":            "\U0001f468\u200d\U0001f4bb",
+	"medal_sports":                "\U0001f3c5",
+	"mega":                        "\U0001f4e3",
+	"memo":                        "\U0001f4dd",
+	"moneybag":                    "\U0001f4b0",
+	"muscle":                      "\U0001f4aa",
+	"negative_squared_cross_mark": "\u274e",
+	"neutral_face":                "\U0001f610",
+	"new":                         "\U0001f195",
+	"no_bell":                     "\U0001f515",
+	"no_entry":                    "\u26d4",
+	"no_entry_sign":               "\U0001f6ab",
+	"ok":                          "\U0001f197",
+	"ok_hand":                     "\U0001f44c",
+	"open_file_folder":            "\U0001f4c2",
+	"orange_circle":               "\U0001f7e0",
+	"outbox_tray":                 "\U0001f4e4",
+	"package":                     "\U0001f4e6",
+	"page_facing_up":              "\U0001f4c4",
+	"paperclip":                   "\U0001f4ce",
+	"party_popper":                "\U0001f389",
+	"pencil":                      "\U0001f4dd",
+	"pencil2":                     "\u270f\ufe0f",
+	"penguin":                     "\U0001f427",
+	"pizza":                       "\U0001f355",
+	"point_down":                  "\U0001f447",
+	"point_left":                  "\U0001f448",
+	"point_right":                 "\U0001f449",
+	"point_up":                    "\u261d\ufe0f",
+	"poop":                        "\U0001f4a9",
+	"pray":                        "\U0001f64f",
+	"purple_heart":                "\U0001f49c",
+	"pushpin":                     "\U0001f4cc",
+	"question":                    "\u2753",
+	"rage":                        "\U0001f621",
+	"rainbow":                     "\U0001f308",
+	"raised_hands":                "\U0001f64c",
+	"recycle":                     "\u267b\ufe0f",
+	"red_circle":                  "\U0001f534",
+	"repeat":                      "\U0001f501",
+	"robot":                       "\U0001f916",
+	"rocket":                      "\U0001f680",
+	"rotating_light":              "\U0001f6a8",
+	"scream":                      "\U0001f631",
+	"see_no_evil":                 "\U0001f648",
+	"seedling":                    "\U0001f331",
+	"shield":                      "\U0001f6e1\ufe0f",
+	"skull":                       "\U0001f480",
+	"slightly_smiling_face":       "\U0001f642",
+	"smile":                       "\U0001f604",
+	"smiley":                      "\U0001f603",
+	"snake":                       "\U0001f40d",
+	"snowflake":                   "\u2744\ufe0f",
+	"sob":                         "\U0001f62d",
+	"sos":                         "\U0001f198",
+	"sparkles":                    "\u2728",
+	"star":                        "\u2b50",
+	"star2":                       "\U0001f31f",
+	"stop_sign":                   "\U0001f6d1",
+	"stopwatch":                   "\u23f1\ufe0f",
+	"sun":                         "\u2600\ufe0f",
+	"sunglasses":                  "\U0001f60e",
+	"sunny":                       "\u2600\ufe0f",
+	"tada":                        "\U0001f389",
+	"technologist":                "\U0001f9d1\u200d\U0001f4bb",
+	"thinking":                    "\U0001f914",
+	"thumbsdown":                  "\U0001f44e",
+	"thumbsup":                    "\U0001f44d",
+	"triangular_flag_on_post":     "\U0001f6a9",
+	"trophy":                      "\U0001f3c6",
+	"truck":                       "\U0001f69a",
+	"umbrella":                    "\u2614",
+	"unlock":                      "\U0001f513",
+	"up":                          "\U0001f199",
+	"warning":                     "\u26a0\ufe0f",
+	"wastebasket":                 "\U0001f5d1\ufe0f",
+	"watch":                       "\u231a",
+	"wave":                        "\U0001f44b",
+	"whale":                       "\U0001f433",
+	"white_check_mark":            "\u2705",
+	"white_circle":                "\u26aa",
+	"wink":                        "\U0001f609",
+	"woman_technologist":          "\U0001f469\u200d\U0001f4bb",
+	"worried":                     "\U0001f61f",
+	"wrench":                      "\U0001f527",
+	"x":                           "\u274c",
+	"yellow_circle":               "\U0001f7e1",
+	"yellow_heart":                "\U0001f49b",
+	"zap":                         "\u26a1",
+	"zzz":                         "\U0001f4a4",
+}
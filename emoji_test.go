@@ -0,0 +1,46 @@
+package markout
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_find_emoji(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []string
+	}{
+		{"no shortcodes", nil},
+		{":white_check_mark: passed", []string{"✅"}},
+		{"time 12:30:45 :tada::+1:", []string{"\U0001f389", "\U0001f44d"}},
+		{":unknown:rocket: :x", []string{"\U0001f680"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, m := range find_emoji(tt.s) {
+			got = append(got, m.emoji)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("find_emoji(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func ExampleEmoji() {
+	write_each(func(w Writer) {
+		w.BeginTable("Check", "Status")
+		w.TableRow("build", ":white_check_mark: passed")
+		w.TableRow("lint", ":x: failed")
+		w.EndTable()
+	}, TXTOptions{Emoji: EmojiUnicode}, MDOptions{Emoji: EmojiShortcodes})
+	// Output:
+	// Check Status
+	// ----- ---------
+	// build ✅ passed
+	// lint  ❌ failed
+	//
+	// | Check | Status
+	// |-------|---------------------------
+	// | build | :white_check_mark: passed
+	// | lint  | :x: failed
+}
@@ -0,0 +1,182 @@
+package markout
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// heading_info describes a section heading.
+type heading_info struct {
+	counters []int      // section counters, one per level
+	caption  RawContent // formatted heading content
+	linkable RawContent // formatted heading content without links, written within links to the heading
+	text     string     // plain text heading content
+	attrs    *Attrs     // explicit attributes, may be nil
+	id       string     // explicit or generated identifier
+	anchor   bool       // generated identifier must be written out
+	number   string     // section number without suffix, empty if not numbered
+	suffix   string     // written after the section number
+	appendix bool       // the heading belongs to appendices
+	slug_num bool       // generated identifier includes the section number
+}
+
+// label returns the section number followed by the suffix, or an empty
+// string for unnumbered headings.
+func (h *heading_info) label() string {
+	if h.number == "" {
+		return ""
+	}
+	return h.number + h.suffix
+}
+
+// numbered returns the caption preceeded by the section number.
+func (h *heading_info) numbered(caption RawContent) RawContent {
+	if h.number == "" {
+		return caption
+	}
+	return append([]byte(h.label()+" "), caption...)
+}
+
+func (h *heading_info) level() int {
+	return len(h.counters)
+}
+
+// heading_registry keeps track of the headings written into a document.
+type heading_registry struct {
+	entries []*heading_info
+	by_id   map[string]*heading_info
+	slugs   map[string]int
+}
+
+func (r *heading_registry) add(h *heading_info) {
+	if h.attrs != nil && h.attrs.Identifier != "" {
+		h.id = h.attrs.Identifier
+		if r.slugs == nil {
+			r.slugs = map[string]int{}
+		}
+		if _, seen := r.slugs[h.id]; !seen {
+			r.slugs[h.id] = 0
+		}
+	} else if h.slug_num && h.number != "" {
+		h.id = r.unique(slugify(h.label() + " " + h.text))
+		h.anchor = true
+		// references can also use the identifier of the unnumbered caption
+		r.alias(slugify(h.text), h)
+	} else {
+		h.id = r.unique(slugify(h.text))
+		h.anchor = true
+	}
+	r.alias(h.id, h)
+	r.entries = append(r.entries, h)
+}
+
+// alias makes the heading findable by id, unless the id is already taken.
+func (r *heading_registry) alias(id string, h *heading_info) {
+	if r.by_id == nil {
+		r.by_id = map[string]*heading_info{}
+	}
+	if _, seen := r.by_id[id]; !seen {
+		r.by_id[id] = h
+	}
+}
+
+// find returns the heading with the specified identifier, or nil.
+func (r *heading_registry) find(id string) *heading_info {
+	return r.by_id[id]
+}
+
+// unique de-duplicates identifiers with numeric suffixes, the same way as
+// GitHub does: `id`, `id-1`, `id-2`, ...
+func (r *heading_registry) unique(id string) string {
+	if r.slugs == nil {
+		r.slugs = map[string]int{}
+	}
+	s := id
+	for _, seen := r.slugs[s]; seen; _, seen = r.slugs[s] {
+		r.slugs[id]++
+		s = id + "-" + strconv.Itoa(r.slugs[id])
+	}
+	r.slugs[s] = 0
+	return s
+}
+
+// slugify generates GitHub-compatible heading identifiers: lowercased text
+// with punctuation removed and spaces replaced by hyphens.
+func slugify(text string) string {
+	b := strings.Builder{}
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case r == ' ':
+			b.WriteByte('-')
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// plain_inlines renders inline content as plain text, without any
+// decorations; it is used for generating heading identifiers.
+type plain_inlines struct {
+	txt_inlines
+}
+
+func (ii *plain_inlines) code_raw(b *bytes.Buffer, s RawContent) {
+	b.Write(s)
+}
+func (ii *plain_inlines) code_str(b *bytes.Buffer, s string) {
+	b.WriteString(s)
+}
+func (ii *plain_inlines) begin_styled(b *bytes.Buffer, sty Style) {
+	ii.start_styled(sty)
+	ii.style_offsets = append(ii.style_offsets, b.Len())
+}
+func (ii *plain_inlines) end_styled(b *bytes.Buffer) {
+	ii.finish_styled()
+	ii.style_offsets = ii.style_offsets[:len(ii.style_offsets)-1]
+}
+func (ii *plain_inlines) begin_link(b *bytes.Buffer, url RawContent) {
+	ii.pending_link = &url
+}
+func (ii *plain_inlines) end_link(b *bytes.Buffer) {
+	ii.pending_link = nil
+}
+func (ii *plain_inlines) simple_link(b *bytes.Buffer, caption RawContent, url RawContent, la *LinkAttrs) {
+	if len(caption) == 0 {
+		b.Write(url)
+	} else {
+		b.Write(caption)
+	}
+}
+
+// unlinked_inlines renders inline content with the links replaced by their
+// captions; it is used for the content written within other links, such as
+// table of contents entries.
+type unlinked_inlines struct {
+	inlines
+}
+
+func (ii unlinked_inlines) begin_link(b *bytes.Buffer, url RawContent) {
+}
+func (ii unlinked_inlines) end_link(b *bytes.Buffer) {
+}
+func (ii unlinked_inlines) simple_link(b *bytes.Buffer, caption RawContent, url RawContent, la *LinkAttrs) {
+	if len(caption) == 0 {
+		ii.suppress_smart(true)
+		ii.put_str(b, string(url))
+		ii.suppress_smart(false)
+	} else {
+		b.Write(caption)
+	}
+}
+func (ii unlinked_inlines) anchor_link(b *bytes.Buffer, caption RawContent, id string) {
+	b.Write(caption)
+}
+
+func plain_text(a any) string {
+	buf := bytes.Buffer{}
+	to_buffer(&buf, &plain_inlines{}, nil, a)
+	return buf.String()
+}
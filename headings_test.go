@@ -0,0 +1,65 @@
+package markout
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_heading_registry_unique(t *testing.T) {
+	r := heading_registry{}
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Hello, World!", "hello-world"},
+		{"Hello World", "hello-world-1"},
+		{"hello-world-1", "hello-world-1-1"},
+		{"Hello world", "hello-world-2"},
+		{"Ünïcode Tëxt", "ünïcode-tëxt"},
+		{"snake_case and  spaces", "snake_case-and--spaces"},
+		{"", ""},
+		{"", "-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := r.unique(slugify(tt.text)); got != tt.want {
+				t.Errorf("unique(slugify(%q)) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_heading_registry_numbered(t *testing.T) {
+	r := heading_registry{}
+	h := &heading_info{counters: []int{1}, text: "Intro", number: "1", suffix: ".", slug_num: true}
+	r.add(h)
+	if h.id != "1-intro" {
+		t.Errorf("id = %q, want %q", h.id, "1-intro")
+	}
+	if r.find("intro") != h || r.find("1-intro") != h {
+		t.Errorf("heading is not found by the numbered and unnumbered ids")
+	}
+}
+
+func Test_toc_unlinked(t *testing.T) {
+	write := func(w Writer) {
+		w.TableOfContents(0)
+		w.BeginSection(Link("Intro", "https://example.com"))
+		w.EndSection()
+		w.BeginSectionf("%s notes", Ref("intro", RefCaption))
+		w.EndSection()
+		w.Close()
+	}
+	b := bytes.Buffer{}
+	write(NewMD(&b, MDOptions{}))
+	want := "- [Intro](#intro)\n- [Intro notes](#intro-notes)\n\n"
+	if got := b.String(); !bytes.HasPrefix([]byte(got), []byte(want)) {
+		t.Errorf("got %q, want prefix %q", got, want)
+	}
+	b.Reset()
+	write(NewHTML(&b, HTMLOptions{}))
+	want = `<li><a href="#intro">Intro</a></li>` + "\n" + `<li><a href="#intro-notes">Intro notes</a></li>`
+	if got := b.String(); !bytes.Contains([]byte(got), []byte(want)) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
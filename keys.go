@@ -0,0 +1,54 @@
+package markout
+
+// Keys creates an inline value for keyboard shortcuts, such as
+// Keys("Ctrl", "Shift", "P"). The keys are written as a sequence of keyboard
+// spans joined with '+': `<kbd>Ctrl</kbd>+<kbd>Shift</kbd>+<kbd>P</kbd>` in
+// HTML and markdown, `Ctrl+Shift+P` in plain text.
+func Keys(keys ...string) key_combo {
+	return key_combo(keys)
+}
+
+// MenuPath creates an inline value for the paths to menu commands, such as
+// MenuPath("File", "Export", "PDF"). HTML targets write the items separated
+// with arrows within a span of the "menu" class, other targets use '>' as a
+// separator: `File > Export > PDF`.
+func MenuPath(items ...string) menu_path {
+	return menu_path(items)
+}
+
+type key_combo []string
+
+type menu_path []string
+
+// MarshalMarkoutInline implements InlineMarshaler.
+func (k key_combo) MarshalMarkoutInline(p Printer) error {
+	for i, key := range k {
+		if i > 0 {
+			p.WriteRawBytes([]byte{'+'})
+		}
+		p.Styled(KeyboardStyle, key)
+	}
+	return nil
+}
+
+// MarshalMarkoutInline implements InlineMarshaler.
+func (m menu_path) MarshalMarkoutInline(p Printer) error {
+	if p.Format() != FormatHTML {
+		for i, item := range m {
+			if i > 0 {
+				p.WriteString(" > ")
+			}
+			p.WriteString(item)
+		}
+		return nil
+	}
+	p.AttrSpan(Attrs{Classes: []string{"menu"}}, Callback(func(p Printer) {
+		for i, item := range m {
+			if i > 0 {
+				p.WriteRawBytes([]byte(" &rarr; "))
+			}
+			p.WriteString(item)
+		}
+	}))
+	return nil
+}
@@ -0,0 +1,18 @@
+package markout
+
+func ExampleKeys() {
+	write_each(func(w Writer) {
+		w.Paraf("Press %s, or choose %s.", Keys("Ctrl", "Shift", "P"), MenuPath("File", "Export", "PDF"))
+	}, TXTOptions{}, MDOptions{}, HTMLOptions{})
+	// Output:
+	// Press Ctrl+Shift+P, or choose File > Export > PDF.
+	//
+	// Press <kbd>Ctrl</kbd>+<kbd>Shift</kbd>+<kbd>P</kbd>, or choose File \> Export \> PDF\.
+	//
+	// <html>
+	// <body>
+	// <p>Press <kbd>Ctrl</kbd>+<kbd>Shift</kbd>+<kbd>P</kbd>, or choose <span class="menu">File &rarr; Export &rarr; PDF</span>.</p>
+	//
+	// </body>
+	// </html>
+}
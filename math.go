@@ -0,0 +1,49 @@
+package markout
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Math creates a wrapper for inline formulas written in TeX notation. The
+// formula is written without escaping: `$...$` in markdown, `\(...\)` in
+// HTML (compatible with MathJax and KaTeX), and as a code span in plain text.
+func Math(tex string) math_span {
+	return math_span(tex)
+}
+
+type math_span string
+
+// MathRenderer converts TeX formulas to HTML content, such as MathML or SVG.
+// Display formulas are written with MathBlock, others are inline.
+type MathRenderer interface {
+	RenderMath(tex string, display bool) (RawContent, error)
+}
+
+// render_math uses r to render the formula, renderer failures are recorded
+// as document errors and the formula falls back to TeX delimiters.
+func render_math(b *bytes.Buffer, r MathRenderer, doc *document, tex string, display bool) bool {
+	if r == nil {
+		return false
+	}
+	s, err := r.RenderMath(tex, display)
+	if err != nil {
+		if doc != nil {
+			doc.failures = append(doc.failures, math_error(tex, err))
+		}
+		return false
+	}
+	b.Write(s)
+	return true
+}
+
+func math_error(tex string, err error) error {
+	return fmt.Errorf("markout: rendering math %q: %w", tex, err)
+}
+
+// source_lines normalizes line endings and trims empty leading and trailing
+// lines.
+func source_lines(s string) string {
+	return strings.Trim(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
@@ -0,0 +1,57 @@
+package markout
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+type mathml_renderer struct{}
+
+func (mathml_renderer) RenderMath(tex string, display bool) (RawContent, error) {
+	if tex != "x^2" {
+		return nil, errors.New("unsupported")
+	}
+	return RawContent("<math><msup><mi>x</mi><mn>2</mn></msup></math>"), nil
+}
+
+func ExampleMath() {
+	write_each(func(w Writer) {
+		w.Paraf("Energy: %s, where %s.", Math("E = mc^2"), Math(`c_{light} < \infty`))
+		w.MathBlock(`\sum_{i=1}^{n} i = \frac{n(n+1)}{2}`)
+	}, TXTOptions{}, MDOptions{}, HTMLOptions{})
+
+	w := NewHTML(os.Stdout, HTMLOptions{MathRenderer: mathml_renderer{}})
+	w.Para(Math("x^2"))
+	w.MathBlock("y^2")
+	w.Close()
+	fmt.Println(w.Err())
+	// Output:
+	// Energy: `E = mc^2`, where `c_{light} < \infty`.
+	//
+	//     \sum_{i=1}^{n} i = \frac{n(n+1)}{2}
+	//
+	// Energy: $E = mc^2$, where $c_{light} < \infty$\.
+	//
+	// $$
+	// \sum_{i=1}^{n} i = \frac{n(n+1)}{2}
+	// $$
+	//
+	// <html>
+	// <body>
+	// <p>Energy: <span class="math inline">\(E = mc^2\)</span>, where <span class="math inline">\(c_{light} &lt; \infty\)</span>.</p>
+	//
+	// <div class="math display">\[\sum_{i=1}^{n} i = \frac{n(n+1)}{2}\]</div>
+	//
+	// </body>
+	// </html>
+	// <html>
+	// <body>
+	// <p><math><msup><mi>x</mi><mn>2</mn></msup></math></p>
+	//
+	// <div class="math display">\[y^2\]</div>
+	//
+	// </body>
+	// </html>
+	// markout: rendering math "y^2": unsupported
+}
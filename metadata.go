@@ -0,0 +1,218 @@
+package markout
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/exp/slices"
+)
+
+// Metadata holds document metadata, such as title, author, date, tags, or
+// arbitrary keys. Values can be strings, numbers, booleans, time.Time, or
+// slices of those; nil values are skipped.
+type Metadata map[string]any
+
+// FrontMatter specifies the format of the metadata block written at the
+// beginning of markdown documents.
+type FrontMatter int
+
+const (
+	YAMLFrontMatter = FrontMatter(iota)
+	TOMLFrontMatter
+)
+
+// well-known keys come first, the rest is sorted
+var meta_key_order = []string{"title", "author", "date"}
+
+func (m Metadata) keys() []string {
+	r := make([]string, 0, len(m))
+	for _, k := range meta_key_order {
+		if v, ok := m[k]; ok && v != nil {
+			r = append(r, k)
+		}
+	}
+	n := len(r)
+	for k, v := range m {
+		if v != nil && !slices.Contains(meta_key_order, k) {
+			r = append(r, k)
+		}
+	}
+	sort.Strings(r[n:])
+	return r
+}
+
+// meta_value is a scalar metadata value, quoted values are written as strings
+// in front matter.
+type meta_value struct {
+	s         string
+	quoted    bool
+	nonfinite bool // NaN or infinity, written as `NaN`, `+Inf`, or `-Inf`
+}
+
+// meta_values converts a metadata value to a list of scalars, list is true
+// for slices and arrays.
+func meta_values(v any) (values []meta_value, list bool) {
+	val := reflect.ValueOf(v)
+	if val.IsValid() && (val.Kind() == reflect.Slice || val.Kind() == reflect.Array) && val.Type().Elem().Kind() != reflect.Uint8 {
+		for i := 0; i < val.Len(); i++ {
+			if e := val.Index(i).Interface(); e != nil {
+				values = append(values, meta_scalar(e))
+			}
+		}
+		return values, true
+	}
+	return []meta_value{meta_scalar(v)}, false
+}
+
+func meta_scalar(v any) meta_value {
+	switch v := v.(type) {
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 {
+			return meta_value{s: v.Format("2006-01-02")}
+		}
+		return meta_value{s: v.Format(time.RFC3339)}
+	case bool:
+		return meta_value{s: strconv.FormatBool(v)}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return meta_value{s: fmt.Sprint(v)}
+	case float32:
+		return meta_value{s: strconv.FormatFloat(float64(v), 'g', -1, 32), nonfinite: is_nonfinite(float64(v))}
+	case float64:
+		return meta_value{s: strconv.FormatFloat(v, 'g', -1, 64), nonfinite: is_nonfinite(v)}
+	case []byte:
+		return meta_value{s: string(v), quoted: true}
+	default:
+		return meta_value{s: fmt.Sprint(v), quoted: true}
+	}
+}
+
+func is_nonfinite(v float64) bool {
+	return math.IsNaN(v) || math.IsInf(v, 0)
+}
+
+// front_matter_nonfinite spells NaN and infinities in YAML and TOML.
+var front_matter_nonfinite = map[FrontMatter]map[string]string{
+	YAMLFrontMatter: {"NaN": ".nan", "+Inf": ".inf", "-Inf": "-.inf"},
+	TOMLFrontMatter: {"NaN": "nan", "+Inf": "inf", "-Inf": "-inf"},
+}
+
+// meta_text formats a metadata value as plain text.
+func meta_text(v any) string {
+	values, _ := meta_values(v)
+	ss := make([]string, len(values))
+	for i, mv := range values {
+		ss[i] = strings.Join(strings.Fields(mv.s), " ")
+	}
+	return strings.Join(ss, ", ")
+}
+
+// quote_basic produces a double-quoted string that is valid in both YAML and
+// TOML.
+func quote_basic(s string) string {
+	b := strings.Builder{}
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// bare_key returns true if k can be written without quotes.
+func bare_key(k string) bool {
+	if k == "" {
+		return false
+	}
+	for _, r := range k {
+		if r > unicode.MaxASCII || !(r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// front_matter formats metadata as YAML or TOML front matter block.
+func (m Metadata) front_matter(f FrontMatter) RawContent {
+	delim, assign := "---", ": "
+	if f == TOMLFrontMatter {
+		delim, assign = "+++", " = "
+	}
+	b := strings.Builder{}
+	b.WriteString(delim)
+	for _, k := range m.keys() {
+		b.WriteByte('\n')
+		if bare_key(k) {
+			b.WriteString(k)
+		} else {
+			b.WriteString(quote_basic(k))
+		}
+		b.WriteString(assign)
+		values, list := meta_values(m[k])
+		if list {
+			b.WriteByte('[')
+		}
+		for i, mv := range values {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			if mv.quoted {
+				b.WriteString(quote_basic(mv.s))
+			} else if mv.nonfinite {
+				b.WriteString(front_matter_nonfinite[f][mv.s])
+			} else {
+				b.WriteString(mv.s)
+			}
+		}
+		if list {
+			b.WriteByte(']')
+		}
+	}
+	b.WriteByte('\n')
+	b.WriteString(delim)
+	return RawContent(b.String())
+}
+
+// text_block formats metadata as plain text `Key: value` lines.
+func (m Metadata) text_block() RawContent {
+	b := strings.Builder{}
+	for i, k := range m.keys() {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		rr := []rune(k)
+		if len(rr) > 0 {
+			rr[0] = unicode.ToUpper(rr[0])
+		}
+		b.WriteString(string(rr))
+		b.WriteString(": ")
+		b.WriteString(meta_text(m[k]))
+	}
+	return RawContent(b.String())
+}
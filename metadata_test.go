@@ -0,0 +1,85 @@
+package markout
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func ExampleMetadata() {
+	meta := Metadata{
+		"title":  `Release "Notes"`,
+		"author": "R&D <team>",
+		"date":   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		"tags":   []string{"go", "docs"},
+		"draft":  false,
+	}
+	write_each(func(w Writer) {
+		w.Para("Text")
+	}, TXTOptions{Metadata: meta}, MDOptions{Metadata: meta}, MDOptions{Metadata: meta, FrontMatter: TOMLFrontMatter}, HTMLOptions{Metadata: meta})
+	// Output:
+	// Title: Release "Notes"
+	// Author: R&D <team>
+	// Date: 2024-03-01
+	// Draft: false
+	// Tags: go, docs
+	//
+	// Text
+	//
+	// ---
+	// title: "Release \"Notes\""
+	// author: "R&D <team>"
+	// date: 2024-03-01
+	// draft: false
+	// tags: ["go", "docs"]
+	// ---
+	//
+	// Text
+	//
+	// +++
+	// title = "Release \"Notes\""
+	// author = "R&D <team>"
+	// date = 2024-03-01
+	// draft = false
+	// tags = ["go", "docs"]
+	// +++
+	//
+	// Text
+	//
+	// <html>
+	// <head>
+	//   <title>Release "Notes"</title>
+	//   <meta name="author" content="R&amp;D &lt;team&gt;">
+	//   <meta name="date" content="2024-03-01">
+	//   <meta name="draft" content="false">
+	//   <meta name="keywords" content="go, docs">
+	// </head>
+	// <body>
+	// <p>Text</p>
+	//
+	// </body>
+	// </html>
+}
+
+func TestMetadata_front_matter(t *testing.T) {
+	meta := Metadata{
+		"ratio":  math.NaN(),
+		"limits": []any{math.Inf(-1), nil, 1.5, math.Inf(1)},
+		"author": nil,
+	}
+	tests := []struct {
+		f    FrontMatter
+		want string
+	}{
+		{YAMLFrontMatter, "---\nlimits: [-.inf, 1.5, .inf]\nratio: .nan\n---"},
+		{TOMLFrontMatter, "+++\nlimits = [-inf, 1.5, inf]\nratio = nan\n+++"},
+	}
+	for _, tt := range tests {
+		if got := string(meta.front_matter(tt.f)); got != tt.want {
+			t.Errorf("front_matter(%d) = %q, want %q", tt.f, got, tt.want)
+		}
+	}
+	if got, want := string(meta.text_block()), "Limits: -Inf, 1.5, +Inf\nRatio: NaN"; got != want {
+		t.Errorf("text_block() = %q, want %q", got, want)
+	}
+}
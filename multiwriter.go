@@ -38,6 +38,16 @@ func (w *MultiWriter) Close() {
 	}
 }
 
+// Err returns the first error reported by the targets.
+func (w *MultiWriter) Err() error {
+	for t := range w.targets {
+		if err := t.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (w *MultiWriter) Para(a any) {
 	for t := range w.targets {
 		t.Para(a)
@@ -80,6 +90,12 @@ func (w *MultiWriter) EndSection() {
 	}
 }
 
+func (w *MultiWriter) StartAppendices() {
+	for t := range w.targets {
+		t.StartAppendices()
+	}
+}
+
 func (w *MultiWriter) Section(a any) {
 	for t := range w.targets {
 		t.Section(a)
@@ -104,12 +120,24 @@ func (w *MultiWriter) AttrSectionf(aa Attrs, format string, args ...any) {
 	}
 }
 
+func (w *MultiWriter) TableOfContents(max_level int) {
+	for t := range w.targets {
+		t.TableOfContents(max_level)
+	}
+}
+
 func (w *MultiWriter) BeginTable(first_column any, other_columns ...any) {
 	for t := range w.targets {
 		t.BeginTable(first_column, other_columns...)
 	}
 }
 
+func (w *MultiWriter) BeginTableEx(spec TableSpec) {
+	for t := range w.targets {
+		t.BeginTableEx(spec)
+	}
+}
+
 func (w *MultiWriter) TableRow(first_cell any, other_cells ...any) {
 	for t := range w.targets {
 		t.TableRow(first_cell, other_cells...)
@@ -128,6 +156,12 @@ func (w *MultiWriter) BeginList(flags ListFlags) {
 	}
 }
 
+func (w *MultiWriter) BeginListEx(spec ListSpec) {
+	for t := range w.targets {
+		t.BeginListEx(spec)
+	}
+}
+
 func (w *MultiWriter) ListTitle(a any) {
 	for t := range w.targets {
 		t.ListTitle(a)
@@ -152,6 +186,18 @@ func (w *MultiWriter) ListItemf(format string, args ...any) {
 	}
 }
 
+func (w *MultiWriter) TaskItem(checked bool, a any) {
+	for t := range w.targets {
+		t.TaskItem(checked, a)
+	}
+}
+
+func (w *MultiWriter) TaskItemf(checked bool, format string, args ...any) {
+	for t := range w.targets {
+		t.TaskItemf(checked, format, args...)
+	}
+}
+
 func (w *MultiWriter) EndList() {
 	for t := range w.targets {
 		t.EndList()
@@ -185,6 +231,42 @@ func (w *MultiWriter) List(flags ListFlags, items func(ListWriter)) {
 	items(w)
 }
 
+func (w *MultiWriter) RawBlock(format string, content string) {
+	for t := range w.targets {
+		t.RawBlock(format, content)
+	}
+}
+
+func (w *MultiWriter) MathBlock(tex string) {
+	for t := range w.targets {
+		t.MathBlock(tex)
+	}
+}
+
+func (w *MultiWriter) Diagram(kind, source string) {
+	for t := range w.targets {
+		t.Diagram(kind, source)
+	}
+}
+
+func (w *MultiWriter) DefineTerm(term, definition string) {
+	for t := range w.targets {
+		t.DefineTerm(term, definition)
+	}
+}
+
+func (w *MultiWriter) ThematicBreak() {
+	for t := range w.targets {
+		t.ThematicBreak()
+	}
+}
+
+func (w *MultiWriter) PageBreak() {
+	for t := range w.targets {
+		t.PageBreak()
+	}
+}
+
 func (w *MultiWriter) Codeblock(lang string, lines string) {
 	for t := range w.targets {
 		t.Codeblock(lang, lines)
@@ -9,6 +9,7 @@ func NewNULL() Writer {
 
 func (w *null_impl) Close()                                                  {}
 func (w *null_impl) CloseEx(func(ParagraphWriter))                           {}
+func (w *null_impl) Err() error                                              { return nil }
 func (w *null_impl) DisableOutput()                                          {}
 func (w *null_impl) EnableOutput()                                           {}
 func (w *null_impl) Para(any)                                                {}
@@ -22,15 +23,27 @@ func (w *null_impl) Section(any)                                             {}
 func (w *null_impl) Sectionf(string, ...any)                                 {}
 func (w *null_impl) AttrSection(Attrs, any)                                  {}
 func (w *null_impl) AttrSectionf(Attrs, string, ...any)                      {}
+func (w *null_impl) StartAppendices()                                        {}
+func (w *null_impl) TableOfContents(int)                                     {}
 func (w *null_impl) BeginTable(first_column any, other_columns ...any)       {}
+func (w *null_impl) BeginTableEx(TableSpec)                                  {}
 func (w *null_impl) TableRow(first_cell any, other_cells ...any)             {}
 func (w *null_impl) EndTable()                                               {}
 func (w *null_impl) Table(columns []any, rows func(callback TableRowWriter)) {}
 func (w *null_impl) ListTitle(any)                                           {}
 func (w *null_impl) ListTitlef(format string, args ...any)                   {}
 func (w *null_impl) BeginList(ListFlags)                                     {}
+func (w *null_impl) BeginListEx(ListSpec)                                    {}
 func (w *null_impl) EndList()                                                {}
 func (w *null_impl) ListItem(any)                                            {}
 func (w *null_impl) ListItemf(format string, args ...any)                    {}
+func (w *null_impl) TaskItem(bool, any)                                      {}
+func (w *null_impl) TaskItemf(bool, string, ...any)                          {}
 func (w *null_impl) List(ListFlags, func(ListWriter))                        {}
 func (w *null_impl) Codeblock(lang string, lines string)                     {}
+func (w *null_impl) RawBlock(format string, content string)                  {}
+func (w *null_impl) MathBlock(string)                                        {}
+func (w *null_impl) Diagram(kind, source string)                             {}
+func (w *null_impl) ThematicBreak()                                          {}
+func (w *null_impl) DefineTerm(term, definition string)                      {}
+func (w *null_impl) PageBreak()                                              {}
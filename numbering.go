@@ -0,0 +1,144 @@
+package markout
+
+import (
+	"strconv"
+	"strings"
+)
+
+// NumberStyle specifies how ordered list items and section headings are
+// numbered.
+type NumberStyle int
+
+const (
+	NumberDecimal    = NumberStyle(iota) // 1, 2, 3
+	NumberLowerAlpha                     // a, b, c
+	NumberUpperAlpha                     // A, B, C
+	NumberLowerRoman                     // i, ii, iii
+	NumberUpperRoman                     // I, II, III
+)
+
+// Format returns the number n formatted in the style s. Alphabetic styles
+// continue with double letters after z (aa, ab, ...), roman styles fall back
+// to decimal numbers for values that have no roman representation.
+func (s NumberStyle) Format(n int) string {
+	switch s {
+	case NumberLowerAlpha:
+		return alpha_number(n, 'a')
+	case NumberUpperAlpha:
+		return alpha_number(n, 'A')
+	case NumberLowerRoman:
+		return strings.ToLower(roman_number(n))
+	case NumberUpperRoman:
+		return roman_number(n)
+	default:
+		return strconv.Itoa(n)
+	}
+}
+
+// html_type returns the value of the type attribute for the <ol> element.
+func (s NumberStyle) html_type() string {
+	switch s {
+	case NumberLowerAlpha:
+		return "a"
+	case NumberUpperAlpha:
+		return "A"
+	case NumberLowerRoman:
+		return "i"
+	case NumberUpperRoman:
+		return "I"
+	default:
+		return ""
+	}
+}
+
+func alpha_number(n int, first byte) string {
+	if n <= 0 {
+		return strconv.Itoa(n)
+	}
+	b := []byte{}
+	for n > 0 {
+		n--
+		b = append([]byte{first + byte(n%26)}, b...)
+		n /= 26
+	}
+	return string(b)
+}
+
+func roman_number(n int) string {
+	if n <= 0 || n >= 4000 {
+		return strconv.Itoa(n)
+	}
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	digits := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+	b := strings.Builder{}
+	for i, v := range values {
+		for n >= v {
+			b.WriteString(digits[i])
+			n -= v
+		}
+	}
+	return b.String()
+}
+
+// SectionNumbering configures the numbers written before section headings,
+// for example `1.2.`, `II-3)`, or `A.1`.
+type SectionNumbering struct {
+	// Styles specifies the numbering of each level, starting with
+	// FirstLevel. The last style is repeated for deeper levels, decimal
+	// numbers are used when empty.
+	Styles []NumberStyle
+
+	// Separator is written between the numbers of nested levels, defaults
+	// to ".".
+	Separator string
+
+	// Suffix is written after the number, e.g. "." or ")".
+	Suffix string
+
+	// FirstLevel is the first numbered section level, headings at lower
+	// levels are not numbered. Defaults to 1.
+	FirstLevel int
+
+	// AppendixStyles specifies the numbering of the levels that follow
+	// StartAppendices(), starting with the level where it was called.
+	// Defaults to upper-case letters followed by decimal numbers.
+	AppendixStyles []NumberStyle
+}
+
+// number formats section counters, appendix is the level where appendices
+// start, or 0. Returns an empty string for unnumbered levels.
+func (sn *SectionNumbering) number(counters []int, appendix int) string {
+	first := sn.FirstLevel
+	if first < 1 {
+		first = 1
+	}
+	if len(counters) < first {
+		return ""
+	}
+	sep := sn.Separator
+	if sep == "" {
+		sep = "."
+	}
+	b := strings.Builder{}
+	for level := first; level <= len(counters); level++ {
+		if level > first {
+			b.WriteString(sep)
+		}
+		styles, i := sn.Styles, level-first
+		if appendix > 0 && level >= appendix {
+			styles, i = sn.AppendixStyles, level-appendix
+			if len(styles) == 0 {
+				styles = []NumberStyle{NumberUpperAlpha, NumberDecimal}
+			}
+		}
+		style := NumberDecimal
+		if len(styles) > 0 {
+			style = styles[len(styles)-1]
+			if i < len(styles) {
+				style = styles[i]
+			}
+		}
+		b.WriteString(style.Format(counters[level-1]))
+	}
+	return b.String()
+}
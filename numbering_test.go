@@ -0,0 +1,114 @@
+package markout
+
+import "testing"
+
+func TestSectionNumbering_number(t *testing.T) {
+	tests := []struct {
+		sn       SectionNumbering
+		counters []int
+		appendix int
+		want     string
+	}{
+		{SectionNumbering{}, []int{1, 2, 3}, 0, "1.2.3"},
+		{SectionNumbering{FirstLevel: 2}, []int{4}, 0, ""},
+		{SectionNumbering{FirstLevel: 2}, []int{4, 2, 1}, 0, "2.1"},
+		{SectionNumbering{Styles: []NumberStyle{NumberUpperRoman, NumberLowerAlpha}, Separator: "-"}, []int{3, 2, 1}, 0, "III-b-a"},
+		{SectionNumbering{}, []int{2, 3}, 1, "B.3"},
+		{SectionNumbering{AppendixStyles: []NumberStyle{NumberLowerAlpha}}, []int{5, 2, 3}, 2, "5.b.c"},
+	}
+	for _, tt := range tests {
+		if got := tt.sn.number(tt.counters, tt.appendix); got != tt.want {
+			t.Errorf("number(%v, %d) = %q, want %q", tt.counters, tt.appendix, got, tt.want)
+		}
+	}
+}
+
+func TestNumberStyle_Format(t *testing.T) {
+	tests := []struct {
+		style NumberStyle
+		n     int
+		want  string
+	}{
+		{NumberDecimal, 12, "12"},
+		{NumberLowerAlpha, 1, "a"},
+		{NumberLowerAlpha, 26, "z"},
+		{NumberLowerAlpha, 27, "aa"},
+		{NumberUpperAlpha, 53, "BA"},
+		{NumberLowerRoman, 4, "iv"},
+		{NumberUpperRoman, 1994, "MCMXCIV"},
+		{NumberUpperRoman, 0, "0"},
+	}
+	for _, tt := range tests {
+		if got := tt.style.Format(tt.n); got != tt.want {
+			t.Errorf("NumberStyle(%d).Format(%d) = %q, want %q", tt.style, tt.n, got, tt.want)
+		}
+	}
+}
+
+func ExampleSectionNumbering() {
+	numbering := &SectionNumbering{
+		Styles:     []NumberStyle{NumberUpperRoman, NumberDecimal},
+		Suffix:     ")",
+		FirstLevel: 2,
+	}
+	write_each(func(w Writer) {
+		w.BeginSection("Manual")
+		w.BeginSection("Installation")
+		w.Section("Requirements")
+		w.EndSection()
+		w.Section("Usage")
+		w.StartAppendices()
+		w.BeginSection("Options")
+		w.Section("Environment")
+		w.EndSection()
+		w.Paraf("See %s.", Ref("environment", RefNumber))
+		w.EndSection()
+	}, TXTOptions{SectionNumbering: numbering}, MDOptions{SectionNumbering: numbering}, HTMLOptions{SectionNumbering: numbering})
+	// Output:
+	// Manual
+	//
+	// I) Installation
+	//
+	// I.1) Requirements
+	//
+	// II) Usage
+	//
+	// A) Options
+	//
+	// A.1) Environment
+	//
+	// See Appendix A.1.
+	//
+	// # Manual
+	//
+	// ## I) Installation
+	//
+	// ### I.1) Requirements
+	//
+	// ## II) Usage
+	//
+	// ## A) Options
+	//
+	// ### A.1) Environment
+	//
+	// See [Appendix A.1](#a1-environment)\.
+	//
+	// <html>
+	// <body>
+	// <h1 id="manual">Manual</h1>
+	//
+	// <h2 id="installation">I) Installation</h2>
+	//
+	// <h3 id="requirements">I.1) Requirements</h3>
+	//
+	// <h2 id="usage">II) Usage</h2>
+	//
+	// <h2 id="options">A) Options</h2>
+	//
+	// <h3 id="environment">A.1) Environment</h3>
+	//
+	// <p>See <a href="#environment">Appendix A.1</a>.</p>
+	//
+	// </body>
+	// </html>
+}
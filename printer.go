@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"golang.org/x/exp/slices"
 )
@@ -22,6 +23,9 @@ type Printer interface {
 	CodeRawBytes([]byte)
 	CodeblockLine(string)
 
+	// Formulas in TeX notation
+	Math(tex string)
+
 	// Inline links
 	BeginLink(url string)
 	EndLink()
@@ -30,10 +34,27 @@ type Printer interface {
 	BeginStyled(Style)
 	EndStyled()
 
+	// Cross-references to section headings
+	Ref(id string, sty RefStyle)
+
+	// Abbreviations and glossary terms
+	Term(term string)
+
+	// LineBreak forces a line break within the current block.
+	LineBreak()
+
+	// Format returns the identifier of the target format: FormatHTML,
+	// FormatMD, or FormatTXT.
+	Format() string
+
 	// High-level api
 	Print(any)
 	Printf(format string, args ...any)
 	SimpleLink(a any, url string)
+	SimpleLinkEx(a any, url string, la LinkAttrs)
+	AttrSpan(aa Attrs, a any)
+	Colored(c Color, a any)
+	Badge(label, value string, c Color)
 	Styled(Style, any)
 }
 
@@ -45,12 +66,74 @@ type printer_impl struct {
 	buf        *bytes.Buffer
 	ii         inlines
 	url_filter url_filter
+	in_format  bool // printing a Printf format, links with verbs are kept as text
 }
 
 func (p *printer_impl) WriteString(s string) {
 	p.ii.check_mode(iflow)
+	if p.ii.emoji() != EmojiNone {
+		p.write_emoji(s)
+		return
+	}
+	p.write_text(s)
+}
+
+// write_text writes the string, with optional autolinking.
+func (p *printer_impl) write_text(s string) {
+	if p.ii.autolink_active() && p.ii.current_mode()&ilink == 0 {
+		p.write_autolinked(s)
+		return
+	}
 	p.ii.put_str(p.buf, s)
 }
+
+// write_emoji writes the string with known emoji shortcodes replaced with
+// Unicode or protected from escaping.
+func (p *printer_impl) write_emoji(s string) {
+	offset := 0
+	for _, m := range find_emoji(s) {
+		if m.start > offset {
+			p.write_text(s[offset:m.start])
+		}
+		if p.ii.emoji() == EmojiUnicode {
+			p.ii.put_str(p.buf, m.emoji)
+		} else {
+			p.ii.put_raw(p.buf, RawContent(s[m.start:m.end]))
+		}
+		offset = m.end
+	}
+	if offset < len(s) {
+		p.write_text(s[offset:])
+	}
+}
+
+// write_autolinked writes the string with URLs and e-mail addresses converted
+// into links.
+func (p *printer_impl) write_autolinked(s string) {
+	offset := 0
+	for _, m := range find_autolinks(s) {
+		if p.in_format && strings.Contains(s[m.start:m.end], "%") {
+			// the link is completed by the arguments
+			continue
+		}
+		if m.start > offset {
+			p.ii.put_str(p.buf, s[offset:m.start])
+		}
+		caption := bytes.Buffer{}
+		p.ii.suppress_smart(true)
+		p.ii.put_str(&caption, s[m.start:m.end])
+		p.ii.suppress_smart(false)
+		url := RawContent(m.url)
+		if p.url_filter != nil {
+			url = p.url_filter(m.url)
+		}
+		p.ii.simple_link(p.buf, caption.Bytes(), url, nil)
+		offset = m.end
+	}
+	if offset < len(s) {
+		p.ii.put_str(p.buf, s[offset:])
+	}
+}
 func (p *printer_impl) WriteRawBytes(s []byte) {
 	p.ii.check_mode(iflow)
 	p.ii.put_raw(p.buf, s)
@@ -67,13 +150,26 @@ func (p *printer_impl) CodeblockLine(s string) {
 	p.ii.check_mode(iflow)
 	p.ii.codeblock_line(p.buf, s)
 }
+func (p *printer_impl) LineBreak() {
+	p.ii.check_mode(iflow)
+	p.ii.line_break(p.buf)
+}
+func (p *printer_impl) Format() string {
+	return p.ii.format()
+}
+func (p *printer_impl) Math(tex string) {
+	p.ii.check_mode(iflow)
+	p.ii.math(p.buf, tex)
+}
 func (p *printer_impl) BeginLink(url string) {
 	p.ii.check_not_mode(ilink)
+	prev := p.ii.text_context(p.buf)
 	if p.url_filter != nil {
 		p.ii.begin_link(p.buf, RawContent(p.url_filter(url)))
 	} else {
 		p.ii.begin_link(p.buf, RawContent(url))
 	}
+	p.ii.mark_text(p.buf, prev)
 }
 func (p *printer_impl) EndLink() {
 	p.ii.check_mode(ilink)
@@ -81,7 +177,12 @@ func (p *printer_impl) EndLink() {
 }
 func (p *printer_impl) BeginStyled(sty Style) {
 	p.ii.check_mode(iflow)
+	prev := p.ii.text_context(p.buf)
 	p.ii.begin_styled(p.buf, sty)
+	if sty == SingleQuotedStyle || sty == DoubleQuotedStyle {
+		prev = 0 // the text is opened by the quotation mark
+	}
+	p.ii.mark_text(p.buf, prev)
 }
 func (p *printer_impl) EndStyled() {
 	p.ii.check_mode(iflow)
@@ -94,19 +195,81 @@ func (p *printer_impl) Print(a any) {
 func (p *printer_impl) Printf(format string, args ...any) {
 	p.ii.check_mode(iflow)
 	scratch := bytes.Buffer{}
-	p.ii.put_str(&scratch, format)
+	fp := printer_impl{buf: &scratch, ii: p.ii, url_filter: p.url_filter, in_format: true}
+	p.ii.mark_text(&scratch, p.ii.text_context(p.buf))
+	fp.WriteString(format)
 	fmt_raw := scratch.String()
 	args_raw := fmt_args(&scratch, p.ii, p.url_filter, args...)
 	fmt.Fprintf(p.buf, fmt_raw, args_raw...)
 }
 func (p *printer_impl) SimpleLink(a any, url string) {
+	p.SimpleLinkEx(a, url, LinkAttrs{})
+}
+func (p *printer_impl) SimpleLinkEx(a any, url string, la LinkAttrs) {
 	p.ii.check_not_mode(ilink)
 	scratch := bytes.Buffer{}
+	p.ii.mark_text(&scratch, p.ii.text_context(p.buf))
+	p.ii.suppress_autolink(true)
 	to_buffer(&scratch, p.ii, p.url_filter, a)
+	p.ii.end_content(&scratch, true) // followed by the end of the caption
+	p.ii.suppress_autolink(false)
 	if p.url_filter != nil {
-		p.ii.simple_link(p.buf, scratch.Bytes(), RawContent(p.url_filter(url)))
+		p.ii.simple_link(p.buf, scratch.Bytes(), RawContent(p.url_filter(url)), &la)
 	} else {
-		p.ii.simple_link(p.buf, scratch.Bytes(), RawContent(url))
+		p.ii.simple_link(p.buf, scratch.Bytes(), RawContent(url), &la)
+	}
+}
+func (p *printer_impl) AttrSpan(aa Attrs, a any) {
+	p.ii.check_mode(iflow)
+	scratch := bytes.Buffer{}
+	p.ii.mark_text(&scratch, p.ii.text_context(p.buf))
+	to_buffer(&scratch, p.ii, p.url_filter, a)
+	p.ii.end_content(&scratch, false)
+	p.ii.attr_span(p.buf, &aa, scratch.Bytes())
+}
+func (p *printer_impl) Colored(c Color, a any) {
+	p.ii.check_mode(iflow)
+	scratch := bytes.Buffer{}
+	p.ii.mark_text(&scratch, p.ii.text_context(p.buf))
+	to_buffer(&scratch, p.ii, p.url_filter, a)
+	p.ii.end_content(&scratch, false)
+	p.ii.colored(p.buf, c, scratch.Bytes())
+}
+func (p *printer_impl) Badge(label, value string, c Color) {
+	p.ii.check_mode(iflow)
+	p.ii.badge(p.buf, &badge_wrapper{label: label, value: value, color: c})
+}
+func (p *printer_impl) Ref(id string, sty RefStyle) {
+	p.ii.check_not_mode(ilink)
+	doc := p.ii.document()
+	if doc == nil {
+		p.ii.put_str(p.buf, id)
+		return
+	}
+	if h := doc.headings.find(id); h != nil {
+		p.ii.anchor_link(p.buf, ref_caption(h, sty), h.id)
+		return
+	}
+	// forward reference
+	ii := p.ii
+	p.ii.put_raw(p.buf, doc.defer_output().placeholder(func() RawContent {
+		b := bytes.Buffer{}
+		if h := doc.headings.find(id); h != nil {
+			ii.anchor_link(&b, ref_caption(h, sty), h.id)
+		} else {
+			doc.unresolved = append(doc.unresolved, id)
+			ii.put_str(&b, id)
+		}
+		return b.Bytes()
+	}))
+}
+func (p *printer_impl) Term(term string) {
+	p.ii.check_mode(iflow)
+	if doc := p.ii.document(); doc != nil {
+		definition, first := doc.terms.use(term)
+		p.ii.term(p.buf, term, definition, first)
+	} else {
+		p.ii.put_str(p.buf, term)
 	}
 }
 func (p *printer_impl) Styled(sty Style, a any) {
@@ -205,24 +368,51 @@ func handle_print_simple(p Printer, val reflect.Value, typ reflect.Type) (bool,
 	return false, nil
 }
 
-func print_any(p Printer, a any) error {
-
-	// handle RawStr first
+// print_wrapped handles writing of the markout wrapper types.
+func print_wrapped(p Printer, a any) bool {
 	switch v := a.(type) {
 	case RawContent:
 		p.WriteRawBytes(v)
-		return nil
 	case codespan:
 		p.CodeString(string(v))
-		return nil
+	case math_span:
+		p.Math(string(v))
 	case link_wrapper:
-		p.SimpleLink(v.caption, v.url)
-		return nil
+		p.SimpleLinkEx(v.caption, v.url, v.attrs)
 	case style_wrapper:
 		p.Styled(v.sty, v.content)
-		return nil
+	case cell_span:
+		p.Print(v.content)
+	case ref_wrapper:
+		p.Ref(v.id, v.sty)
+	case term_wrapper:
+		p.Term(string(v))
+	case line_break:
+		p.LineBreak()
+	case attr_span:
+		p.AttrSpan(v.attrs, v.content)
+	case colored_span:
+		p.Colored(v.color, v.content)
+	case badge_wrapper:
+		p.Badge(v.label, v.value, v.color)
+	case raw_wrapper:
+		if v.format == p.Format() {
+			p.WriteRawBytes(RawContent(v.content))
+		} else if v.fallback != nil {
+			p.Print(v.fallback)
+		}
 	case Callback:
 		v(p)
+	default:
+		return false
+	}
+	return true
+}
+
+func print_any(p Printer, a any) error {
+
+	// handle wrappers first
+	if print_wrapped(p, a) {
 		return nil
 	}
 
@@ -283,7 +473,7 @@ const marshalErr = "#ERR"
 
 // to_buffer converts an argument to RawStr and writes it into b.
 func to_buffer(b *bytes.Buffer, ii inlines, uf url_filter, a any) {
-	p := printer_impl{b, ii, uf}
+	p := printer_impl{buf: b, ii: ii, url_filter: uf}
 	if err := print_any(&p, a); err != nil {
 		p.WriteString(marshalErr)
 	}
@@ -291,26 +481,13 @@ func to_buffer(b *bytes.Buffer, ii inlines, uf url_filter, a any) {
 
 func fmt_args(scratch *bytes.Buffer, ii inlines, uf url_filter, args ...any) []any {
 	r := slices.Clone(args)
-	p := printer_impl{scratch, ii, uf}
+	p := printer_impl{buf: scratch, ii: ii, url_filter: uf}
 
 	for i := range r {
 		scratch.Reset()
 
-		switch v := r[i].(type) {
-		case RawContent:
-			p.WriteRawBytes(v)
-			r[i] = scratch.String()
-			continue
-		case link_wrapper:
-			p.SimpleLink(v.caption, v.url)
-			r[i] = scratch.String()
-			continue
-		case style_wrapper:
-			p.Styled(v.sty, v.content)
-			r[i] = scratch.String()
-			continue
-		case Callback:
-			v(&p)
+		if print_wrapped(&p, r[i]) {
+			ii.end_content(scratch, false)
 			r[i] = scratch.String()
 			continue
 		}
@@ -348,6 +525,7 @@ func fmt_args(scratch *bytes.Buffer, ii inlines, uf url_filter, args ...any) []a
 			if err != nil {
 				p.WriteString("#ERR")
 			}
+			ii.end_content(scratch, false)
 			r[i] = RawContent(scratch.String())
 		}
 	}
@@ -0,0 +1,83 @@
+package markout
+
+import (
+	"bytes"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// smarten converts straight quotes into the configured quotation marks,
+// `--` and `---` into en and em dashes, and `...` into an ellipsis. Quotes
+// are opened at the start of the text, after spaces, and after opening
+// punctuation; the text already written into b provides the context.
+func (ii *base_inlines) smarten(b *bytes.Buffer, s string) string {
+	if !ii.smart || ii.smart_off > 0 || strings.IndexAny(s, "'\"-.") < 0 {
+		return s
+	}
+	prev := ii.text_context(b)
+	opened := false // prev is an opening quotation mark
+	r := strings.Builder{}
+	for i := 0; i < len(s); {
+		c, n := utf8.DecodeRuneInString(s[i:])
+		open := prev == 0 || opened || unicode.IsSpace(prev) || strings.ContainsRune("([{<-/–—", prev)
+		t := string(c)
+		opened = false
+		switch c {
+		case '"':
+			t, opened = pick(open, ii.quote_specs[3], ii.quote_specs[2]), open
+		case '\'':
+			next, _ := utf8.DecodeRuneInString(s[i+n:])
+			if unicode.IsLetter(prev) || unicode.IsDigit(prev) {
+				open = false // apostrophe, as in "don't" and "90's"
+			} else if !unicode.IsLetter(next) && !unicode.IsDigit(next) && next != '%' {
+				// a formatting verb, as in "'%d'", stands for a word
+				open = false
+			}
+			t, opened = pick(open, ii.quote_specs[1], ii.quote_specs[0]), open
+		case '-', '.':
+			k := i
+			for k < len(s) && s[k] == byte(c) {
+				k++
+			}
+			switch {
+			case c == '-' && k-i == 2:
+				t = "–"
+			case c == '-' && k-i == 3:
+				t = "—"
+			case c == '.' && k-i == 3:
+				t = "…"
+			default:
+				t = s[i:k]
+			}
+			n = k - i
+		}
+		r.WriteString(t)
+		prev, _ = utf8.DecodeLastRuneInString(t)
+		i += n
+	}
+	return r.String()
+}
+
+// text_context returns the last text character written into b, or 0 at the
+// start of the text. Opening markup written after mark_text is skipped, so
+// that quotes at the start of a styled span or a link caption are opened.
+func (ii *base_inlines) text_context(b *bytes.Buffer) rune {
+	if b == ii.text_buf {
+		if b.Len() == ii.text_at {
+			return ii.text_prev
+		}
+		ii.text_buf = nil
+	}
+	if b.Len() == 0 {
+		return 0
+	}
+	r, _ := utf8.DecodeLastRune(b.Bytes())
+	return r
+}
+
+// mark_text records that the text continues at the current end of b, with
+// prev as the last text character.
+func (ii *base_inlines) mark_text(b *bytes.Buffer, prev rune) {
+	ii.text_buf, ii.text_at, ii.text_prev = b, b.Len(), prev
+}
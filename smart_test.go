@@ -0,0 +1,71 @@
+package markout
+
+import (
+	"bytes"
+	"testing"
+)
+
+func Test_smarten(t *testing.T) {
+	ii := base_inlines{smart: true}
+	ii.setup_quotation_marks(TypographicalQuotes)
+	tests := []struct {
+		before string // content already written
+		s      string
+		want   string
+	}{
+		{"", `"Hello," she said.`, `“Hello,” she said.`},
+		{"", `'single' and don't`, `‘single’ and don’t`},
+		{"", `pages 10--20 --- or more...`, `pages 10–20 — or more…`},
+		{"", `rule ---- and ..`, `rule ---- and ..`},
+		{"say ", `"it"`, `“it”`},
+		{"word", `'s end`, `’s end`},
+		{"", `("nested 'quotes'")`, `(“nested ‘quotes’”)`},
+	}
+	for _, tt := range tests {
+		b := bytes.Buffer{}
+		b.WriteString(tt.before)
+		if got := ii.smarten(&b, tt.s); got != tt.want {
+			t.Errorf("smarten(%q, %q) = %q, want %q", tt.before, tt.s, got, tt.want)
+		}
+	}
+
+	// the context skips the opening markup
+	html_ii, txt_ii := &html_inlines{}, &txt_inlines{}
+	html_ii.smart, txt_ii.smart = true, true
+	html_ii.setup_quotation_marks(TypographicalQuotes)
+	txt_ii.setup_quotation_marks(TypographicalQuotes)
+	printed := []struct {
+		ii   inlines
+		a    any
+		want string
+	}{
+		{html_ii, Emphasized(`"Hi" there`), `<em>“Hi” there</em>`},
+		{html_ii, Link(`'a'`, "x"), `<a href="x">‘a’</a>`},
+		{html_ii, DoubleQuoted(`'a'`), `“‘a’”`},
+		{txt_ii, Strong(`"Hi"`), `**“Hi”**`},
+		{txt_ii, printf_callback(`'%d'`, 5), `‘5’`},
+		{txt_ii, printf_callback(`say %s`, Strong(`'a'`)), `say **‘a’**`},
+	}
+	for _, tt := range printed {
+		b := bytes.Buffer{}
+		to_buffer(&b, tt.ii, nil, tt.a)
+		if got := b.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func ExampleTXTOptions_smartTypography() {
+	write_each(func(w Writer) {
+		w.Paraf("The \"quick\" fix -- it's %s --- wasn't enough...", Code("a--b"))
+	}, TXTOptions{SmartTypography: true, QuotationMarks: TypographicalQuotes}, HTMLOptions{SmartTypography: true, QuotationMarks: TypographicalQuotes})
+	// Output:
+	// The “quick” fix – it’s `a--b` — wasn’t enough…
+	//
+	// <html>
+	// <body>
+	// <p>The “quick” fix – it’s <code>a--b</code> — wasn’t enough…</p>
+	//
+	// </body>
+	// </html>
+}
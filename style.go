@@ -10,6 +10,21 @@ func URL(url string) link_wrapper {
 	return link_wrapper{url: url}
 }
 
+// LinkAttrs specifies optional link properties. The title is shown as a
+// tooltip, markdown targets write it after the URL. Rel and Target are written
+// only into HTML anchors.
+type LinkAttrs struct {
+	Title  string // tooltip text
+	Rel    string // relationship, such as "noopener noreferrer"
+	Target string // browsing context, such as "_blank"
+}
+
+// With specifies the link title and HTML attributes.
+func (l link_wrapper) With(la LinkAttrs) link_wrapper {
+	l.attrs = la
+	return l
+}
+
 func Code(s string) codespan {
 	return codespan(s)
 }
@@ -34,6 +49,92 @@ func Strong(a any) style_wrapper {
 	return style_wrapper{sty: StrongStyle, content: a}
 }
 
+// Strikethrough creates a wrapper for deleted (struck out) inline spans.
+func Strikethrough(a any) style_wrapper {
+	return style_wrapper{sty: StrikethroughStyle, content: a}
+}
+
+// Underline creates a wrapper for underlined inline spans.
+func Underline(a any) style_wrapper {
+	return style_wrapper{sty: UnderlineStyle, content: a}
+}
+
+// Highlight creates a wrapper for highlighted (marked) inline spans.
+func Highlight(a any) style_wrapper {
+	return style_wrapper{sty: HighlightStyle, content: a}
+}
+
+// Subscript creates a wrapper for subscript inline spans.
+func Subscript(a any) style_wrapper {
+	return style_wrapper{sty: SubscriptStyle, content: a}
+}
+
+// Superscript creates a wrapper for superscript inline spans.
+func Superscript(a any) style_wrapper {
+	return style_wrapper{sty: SuperscriptStyle, content: a}
+}
+
+// SmallCaps creates a wrapper for inline spans rendered in small capitals.
+func SmallCaps(a any) style_wrapper {
+	return style_wrapper{sty: SmallCapsStyle, content: a}
+}
+
+// Keyboard creates a wrapper for keyboard input inline spans.
+func Keyboard(a any) style_wrapper {
+	return style_wrapper{sty: KeyboardStyle, content: a}
+}
+
+// Ref creates a wrapper for cross-references to section headings. The id is
+// either an explicit Attrs.Identifier or an identifier generated from the
+// heading text. References to the headings that are written later in the
+// document are resolved when the writer is closed.
+func Ref(id string, sty RefStyle) ref_wrapper {
+	return ref_wrapper{id: id, sty: sty}
+}
+
+// Format identifiers of the supported targets.
+const (
+	FormatHTML = "html"
+	FormatMD   = "md"
+	FormatTXT  = "txt"
+)
+
+// RawFor creates a wrapper for raw content that is written as-is only into
+// the targets of the specified format, and skipped by others. Use Else() to
+// provide alternative content.
+func RawFor(format string, content string) raw_wrapper {
+	return raw_wrapper{format: format, content: content}
+}
+
+// Else specifies the content that is written into the targets that do not
+// match the format of the raw content. Fallbacks can be chained:
+//
+//	RawFor("html", "<kbd>F1</kbd>").Else(RawFor("md", "`F1`").Else("F1"))
+func (r raw_wrapper) Else(fallback any) raw_wrapper {
+	r.fallback = fallback
+	return r
+}
+
+// LineBreak can be used in Print and Printf arguments to force a line break
+// within a paragraph, list item, or table cell.
+const LineBreak = line_break(0)
+
+type line_break int
+
+// AttrSpan creates a wrapper for inline spans with an identifier, classes,
+// and attributes, such as `<span class="version">` in HTML. In markdown, the
+// attributes are written only when MDOptions.BracketedSpans is set, plain
+// text output ignores the attributes.
+func AttrSpan(aa Attrs, a any) attr_span {
+	return attr_span{attrs: aa, content: a}
+}
+
+// Span creates a wrapper for table cells that span multiple columns and/or
+// rows. Outside of tables, the content is written as-is.
+func Span(a any, cols, rows int) cell_span {
+	return cell_span{content: a, cols: cols, rows: rows}
+}
+
 // Callback is a funcional inline content builder that can be used for complex
 // inline formatting.
 type Callback = func(Printer)
@@ -49,10 +150,26 @@ type Style int
 
 // Accepted Style values:
 const (
-	SingleQuotedStyle = Style(iota) // a fragment surrounded by single quotation marks
-	DoubleQuotedStyle               // a fragment surrounded by double quotation marks
-	EmphasizedStyle                 // emphasized fragment, typically rendered in italic type
-	StrongStyle                     // strong fragment, typically rendered in bold type
+	SingleQuotedStyle  = Style(iota) // a fragment surrounded by single quotation marks
+	DoubleQuotedStyle                // a fragment surrounded by double quotation marks
+	EmphasizedStyle                  // emphasized fragment, typically rendered in italic type
+	StrongStyle                      // strong fragment, typically rendered in bold type
+	StrikethroughStyle               // deleted fragment, rendered with a line through
+	UnderlineStyle                   // underlined fragment
+	HighlightStyle                   // highlighted (marked) fragment
+	SubscriptStyle                   // subscript fragment
+	SuperscriptStyle                 // superscript fragment
+	SmallCapsStyle                   // fragment rendered in small capitals
+	KeyboardStyle                    // keyboard input
+)
+
+// RefStyle specifies the text of cross-references.
+type RefStyle int
+
+// Accepted RefStyle values:
+const (
+	RefCaption = RefStyle(iota) // the caption of the referenced heading
+	RefNumber                   // the number of the referenced heading: "Section 2.3"
 )
 
 // RawContent is the the sequence of bytes that is written out to a target
@@ -65,9 +182,31 @@ type codespan string
 type link_wrapper struct {
 	caption any
 	url     string
+	attrs   LinkAttrs
 }
 
 type style_wrapper struct {
 	sty     Style
 	content any
 }
+
+type cell_span struct {
+	content    any
+	cols, rows int
+}
+
+type attr_span struct {
+	attrs   Attrs
+	content any
+}
+
+type raw_wrapper struct {
+	format   string
+	content  string
+	fallback any
+}
+
+type ref_wrapper struct {
+	id  string
+	sty RefStyle
+}
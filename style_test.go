@@ -0,0 +1,262 @@
+package markout
+
+import (
+	"fmt"
+	"os"
+)
+
+func ExampleRef() {
+	w := NewMD(os.Stdout, MDOptions{})
+	w.Paraf("See %s and %s.", Ref("usage", RefCaption), Ref("details", RefNumber))
+	w.BeginSection("Usage")
+	w.AttrSection(Attrs{Identifier: "details"}, "Details")
+	w.Paraf("Back to %s, broken %s.", Ref("usage", RefNumber), Ref("missing", RefCaption))
+	w.EndSection()
+	w.Close()
+	fmt.Println(w.Err())
+	// Output:
+	// See [Usage](#usage) and [Section 1.1](#details)\.
+	//
+	// # Usage
+	//
+	// ## Details {#details}
+	//
+	// Back to [Section 1](#usage), broken missing\.
+	//
+	// markout: unresolved references: missing
+}
+
+func ExampleSpan() {
+	write_each(func(w Writer) {
+		w.BeginTable("Account", "Q1", "Q2")
+		w.TableRow(Span("Revenue", 1, 2), 10, 20)
+		w.TableRow(30, 40)
+		w.TableRow(Span("Grand total", 2, 1), 100)
+		w.EndTable()
+	}, TXTOptions{}, MDOptions{}, MDOptions{HTMLSpans: true})
+	// Output:
+	// Account  Q1 Q2
+	// -------- -- ---
+	// Revenue  10 20
+	//          30 40
+	// Grand total 100
+	//
+	// | Account     | Q1          | Q2
+	// |-------------|-------------|-----
+	// | Revenue     | 10          | 20
+	// | Revenue     | 30          | 40
+	// | Grand total | Grand total | 100
+	//
+	// <table>
+	// <thead><tr><th>Account</th><th>Q1</th><th>Q2</th></tr></thead>
+	// <tbody>
+	// <tr><td rowspan="2">Revenue</td><td>10</td><td>20</td></tr>
+	// <tr><td>30</td><td>40</td></tr>
+	// <tr><td colspan="2">Grand total</td><td>100</td></tr>
+	// </tbody>
+	// </table>
+}
+
+func ExampleRawFor() {
+	write_each(func(w Writer) {
+		w.Paraf("Press %s to continue.", RawFor(FormatHTML, "<kbd>Enter</kbd>").Else(RawFor(FormatMD, "`Enter`").Else("[Enter]")))
+		w.RawBlock(FormatHTML, `<div class="note">HTML only</div>`)
+		w.RawBlock(FormatMD, "<!-- markdown only -->")
+	}, TXTOptions{}, MDOptions{}, HTMLOptions{})
+	// Output:
+	// Press [Enter] to continue.
+	//
+	// Press `Enter` to continue\.
+	//
+	// <!-- markdown only -->
+	//
+	// <html>
+	// <body>
+	// <p>Press <kbd>Enter</kbd> to continue.</p>
+	//
+	// <div class="note">HTML only</div>
+	//
+	// </body>
+	// </html>
+}
+
+func ExampleLineBreak() {
+	write_each(func(w Writer) {
+		w.Paraf("First line%sSecond line", LineBreak)
+		w.List(Ordered, func(lw ListWriter) {
+			lw.ListItem(func(p Printer) {
+				p.WriteString("Item")
+				p.LineBreak()
+				p.WriteString("continued")
+			})
+		})
+		w.Table([]any{"Address"}, func(row TableRowWriter) {
+			row(func(p Printer) {
+				p.WriteString("1 Main St.")
+				p.LineBreak()
+				p.WriteString("Springfield")
+			})
+		})
+	}, TXTOptions{}, MDOptions{}, HTMLOptions{})
+	// Output:
+	// First line
+	// Second line
+	//
+	// 1. Item
+	//    continued
+	//
+	// Address
+	// -----------
+	// 1 Main St.
+	// Springfield
+	//
+	// First line\
+	// Second line
+	//
+	// 1. Item\
+	//    continued
+	//
+	// | Address
+	// |----------------------------
+	// | 1 Main St\.<br>Springfield
+	//
+	// <html>
+	// <body>
+	// <p>First line<br>Second line</p>
+	//
+	// <ol>
+	//   <li>Item<br>continued</li>
+	// </ol>
+	//
+	// <table>
+	// <thead><tr><th>Address</th></tr></thead>
+	// <tbody>
+	// <tr><td>1 Main St.<br>Springfield</td></tr>
+	// </tbody>
+	// </table>
+	//
+	// </body>
+	// </html>
+}
+
+func ExampleStrikethrough() {
+	write_each(func(w Writer) {
+		w.Paraf("%s %s %s H%sO E=mc%s %s %s",
+			Strikethrough("old"), Underline("key"), Highlight("new"),
+			Subscript(2), Superscript(2), SmallCaps("Caps"), Keyboard("Esc"))
+	}, TXTOptions{}, TXTOptions{
+		CombiningStyles: true,
+		StyleMarkers:    map[Style][2]string{KeyboardStyle: {"<", ">"}},
+	}, MDOptions{}, HTMLOptions{})
+	// Output:
+	// ~~old~~ _key_ ==new== H~2~O E=mc^2 Caps Esc
+	//
+	// o̶l̶d̶ k̲e̲y̲ ==new== H~2~O E=mc^2 Caps <Esc>
+	//
+	// ~~old~~ <u>key</u> <mark>new</mark> H<sub>2</sub>O E=mc<sup>2</sup> <span style="font-variant: small-caps">Caps</span> <kbd>Esc</kbd>
+	//
+	// <html>
+	// <body>
+	// <p><del>old</del> <u>key</u> <mark>new</mark> H<sub>2</sub>O E=mc<sup>2</sup> <span style="font-variant: small-caps">Caps</span> <kbd>Esc</kbd></p>
+	//
+	// </body>
+	// </html>
+}
+
+func ExampleAttrSpan() {
+	version := AttrSpan(Attrs{Classes: []string{"version"}, KeyVals: map[string]string{"data-channel": "stable"}}, "v1.2.0")
+	write_each(func(w Writer) {
+		w.Paraf("Released %s today.", version)
+	}, TXTOptions{}, MDOptions{}, MDOptions{BracketedSpans: true}, HTMLOptions{})
+	// Output:
+	// Released v1.2.0 today.
+	//
+	// Released v1\.2\.0 today\.
+	//
+	// Released [v1\.2\.0]{.version data-channel=stable} today\.
+	//
+	// <html>
+	// <body>
+	// <p>Released <span class="version" data-channel="stable">v1.2.0</span> today.</p>
+	//
+	// </body>
+	// </html>
+}
+
+func ExampleLinkAttrs() {
+	docs := Link("docs", "https://example.com/docs").With(LinkAttrs{Title: "User \"guide\""})
+	write_each(func(w Writer) {
+		w.Section("Overview")
+		w.Paraf("See the %s and the %s.", docs, Link("changelog", "CHANGES.md"))
+		w.Section("Details")
+		w.Paraf("Read the %s again, or visit %s.", docs, Link("home", "https://example.com"))
+	}, MDOptions{}, MDOptions{LinkStyle: MDLinksPerSection}, HTMLOptions{ExternalLinks: LinkAttrs{Rel: "noopener", Target: "_blank"}})
+	// Output:
+	// # Overview
+	//
+	// See the [docs](https://example.com/docs "User \"guide\"") and the [changelog](CHANGES.md)\.
+	//
+	// # Details
+	//
+	// Read the [docs](https://example.com/docs "User \"guide\"") again, or visit [home](https://example.com)\.
+	//
+	// # Overview
+	//
+	// See the [docs][1] and the [changelog][2]\.
+	//
+	// [1]: https://example.com/docs "User \"guide\""
+	// [2]: CHANGES.md
+	//
+	// # Details
+	//
+	// Read the [docs][1] again, or visit [home][3]\.
+	//
+	// [3]: https://example.com
+	//
+	// <html>
+	// <body>
+	// <h1 id="overview">Overview</h1>
+	//
+	// <p>See the <a href="https://example.com/docs" title="User &quot;guide&quot;" rel="noopener" target="_blank">docs</a> and the <a href="CHANGES.md">changelog</a>.</p>
+	//
+	// <h1 id="details">Details</h1>
+	//
+	// <p>Read the <a href="https://example.com/docs" title="User &quot;guide&quot;" rel="noopener" target="_blank">docs</a> again, or visit <a href="https://example.com" rel="noopener" target="_blank">home</a>.</p>
+	//
+	// </body>
+	// </html>
+}
+
+func ExampleSpan_markdown() {
+	write_each(func(w Writer) {
+		w.BeginTableEx(TableSpec{Columns: []TableColumn{{Header: "Tool"}, {Header: "Notes"}}, Caption: Strong("Tools")})
+		w.TableRow(Span(Strong("x"), 2, 1))
+		w.TableRow(Link("go", "https://go.dev"), "1 < 2")
+		w.EndTable()
+	}, MDOptions{HTMLSpans: true, Emphasis: MDEmphasisAsterisk})
+	// Output:
+	// <table>
+	// <caption>
+	//
+	// **Tools**
+	//
+	// </caption>
+	// <thead><tr><th>Tool</th><th>Notes</th></tr></thead>
+	// <tbody>
+	// <tr><td colspan="2">
+	//
+	// **x**
+	//
+	// </td></tr>
+	// <tr><td>
+	//
+	// [go](https://go.dev)
+	//
+	// </td><td>
+	//
+	// 1 \< 2
+	//
+	// </td></tr>
+	// </tbody>
+	// </table>
+}
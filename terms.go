@@ -0,0 +1,72 @@
+package markout
+
+import (
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Term creates a wrapper for abbreviations and glossary terms defined with
+// Writer.DefineTerm(). HTML targets write the definition as a tooltip, plain
+// text targets expand the term on its first use: "Term (definition)".
+func Term(term string) term_wrapper {
+	return term_wrapper(term)
+}
+
+type term_wrapper string
+
+// term_registry keeps track of the terms defined in a document and the order
+// of their first use.
+type term_registry struct {
+	defs      map[string]string
+	used      []string
+	undefined []string
+}
+
+func (r *term_registry) define(term, definition string) {
+	if r.defs == nil {
+		r.defs = map[string]string{}
+	}
+	r.defs[term] = definition
+}
+
+// use returns the definition of the term, and whether it is used for the
+// first time; undefined terms have empty definitions and are recorded for
+// reporting.
+func (r *term_registry) use(term string) (definition string, first bool) {
+	definition, ok := r.defs[term]
+	if !ok {
+		if !slices.Contains(r.undefined, term) {
+			r.undefined = append(r.undefined, term)
+		}
+		return "", false
+	}
+	for _, t := range r.used {
+		if t == term {
+			return definition, false
+		}
+	}
+	r.used = append(r.used, term)
+	return definition, true
+}
+
+// glossary_entry is a term with its definition.
+type glossary_entry struct {
+	term, definition string
+}
+
+// glossary returns the used terms in alphabetical order, skipping the terms
+// with empty definitions.
+func (r *term_registry) glossary() []glossary_entry {
+	ee := make([]glossary_entry, 0, len(r.used))
+	for _, t := range r.used {
+		if d := r.defs[t]; d != "" {
+			ee = append(ee, glossary_entry{t, d})
+		}
+	}
+	sort.SliceStable(ee, func(i, j int) bool {
+		return strings.ToLower(ee[i].term) < strings.ToLower(ee[j].term)
+	})
+	return ee
+}
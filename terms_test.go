@@ -0,0 +1,55 @@
+package markout
+
+import (
+	"fmt"
+	"os"
+)
+
+func ExampleTerm() {
+	write_each(func(w Writer) {
+		w.DefineTerm("SLA", "Service Level Agreement")
+		w.DefineTerm("RPO", "Recovery Point Objective")
+		w.Section("Availability")
+		w.Paraf("The %s defines the %s. Each %s is reviewed yearly.", Term("SLA"), Term("RPO"), Term("SLA"))
+	}, TXTOptions{Glossary: "Glossary"}, MDOptions{Abbreviations: true}, HTMLOptions{})
+	// Output:
+	// Availability
+	//
+	// The SLA (Service Level Agreement) defines the RPO (Recovery Point Objective). Each SLA is reviewed yearly.
+	//
+	// Glossary
+	//
+	// * **RPO**: Recovery Point Objective
+	// * **SLA**: Service Level Agreement
+	//
+	// # Availability
+	//
+	// The SLA defines the RPO\. Each SLA is reviewed yearly\.
+	//
+	// *[RPO]: Recovery Point Objective
+	// *[SLA]: Service Level Agreement
+	//
+	// <html>
+	// <body>
+	// <h1 id="availability">Availability</h1>
+	//
+	// <p>The <abbr title="Service Level Agreement">SLA</abbr> defines the <abbr title="Recovery Point Objective">RPO</abbr>. Each <abbr title="Service Level Agreement">SLA</abbr> is reviewed yearly.</p>
+	//
+	// </body>
+	// </html>
+}
+
+func ExampleTerm_undefined() {
+	w := NewMD(os.Stdout, MDOptions{Abbreviations: true})
+	w.DefineTerm("SLA", "")
+	w.DefineTerm("RPO", "Recovery Point Objective")
+	w.Paraf("The %s sets the %s and the %s.", Term("SLA"), Term("RPO"), Term("XYZ"))
+	w.Close()
+	fmt.Println(w.Err())
+	// Output:
+	// The SLA sets the RPO and the XYZ\.
+	//
+	// *[RPO]: Recovery Point Objective
+	//
+	// markout: undefined terms: XYZ
+}
@@ -60,6 +60,22 @@ var t = []cprange{
 }
 
 func find(r rune) bool {
+	return find_in(t, r)
+}
+
+// emoji lists the ranges of emoji that are displayed in wide (emoji)
+// presentation by default.
+var emoji = []cprange{
+	{0x231a, 0x231b}, {0x23e9, 0x23ec}, {0x23f0, 0x23f0}, {0x23f3, 0x23f3}, {0x25fd, 0x25fe}, {0x2614, 0x2615}, {0x2648, 0x2653},
+	{0x267f, 0x267f}, {0x2693, 0x2693}, {0x26a1, 0x26a1}, {0x26aa, 0x26ab}, {0x26bd, 0x26be}, {0x26c4, 0x26c5}, {0x26ce, 0x26ce},
+	{0x26d4, 0x26d4}, {0x26ea, 0x26ea}, {0x26f2, 0x26f3}, {0x26f5, 0x26f5}, {0x26fa, 0x26fa}, {0x26fd, 0x26fd}, {0x2705, 0x2705},
+	{0x270a, 0x270b}, {0x2728, 0x2728}, {0x274c, 0x274c}, {0x274e, 0x274e}, {0x2753, 0x2755}, {0x2757, 0x2757}, {0x2795, 0x2797},
+	{0x27b0, 0x27b0}, {0x27bf, 0x27bf}, {0x2b1b, 0x2b1c}, {0x2b50, 0x2b50}, {0x2b55, 0x2b55}, {0x1f004, 0x1f004}, {0x1f0cf, 0x1f0cf},
+	{0x1f18e, 0x1f18e}, {0x1f191, 0x1f19a}, {0x1f200, 0x1f202}, {0x1f210, 0x1f23b}, {0x1f240, 0x1f248}, {0x1f250, 0x1f251}, {0x1f260, 0x1f265},
+	{0x1f300, 0x1f64f}, {0x1f680, 0x1f6ff}, {0x1f7e0, 0x1f7eb}, {0x1f7f0, 0x1f7f0}, {0x1f900, 0x1f9ff}, {0x1fa70, 0x1faff},
+}
+
+func find_in(t []cprange, r rune) bool {
 	if r < t[0].lo {
 		return false
 	}
@@ -92,6 +108,9 @@ func RuneCells(r rune) int {
 	if find(r) {
 		return 0
 	}
+	if find_in(emoji, r) {
+		return 2
+	}
 	if r >= 0x1100 &&
 		(r <= 0x115f || // Hangul Jamo init. consonants
 			r == 0x2329 || r == 0x232a ||
@@ -110,11 +129,23 @@ func RuneCells(r rune) int {
 	return 1
 }
 
-// StringCells returns the number of character cells required to display the string.
+// StringCells returns the number of character cells required to display the
+// string. Emoji sequences are taken into account: the variation selector
+// U+FE0F widens the preceding character, and the characters joined with
+// U+200D (ZWJ) are displayed as a single emoji.
 func StringCells(s string) int {
 	w := 0
+	prev, prev_w := rune(0), 0
 	for _, r := range s {
-		w += RuneCells(r)
+		rw := RuneCells(r)
+		switch {
+		case r == 0xfe0f && prev_w == 1:
+			rw = 1
+		case prev == 0x200d && rw == 2:
+			rw = 0
+		}
+		w += rw
+		prev, prev_w = r, rw
 	}
 	return w
 }
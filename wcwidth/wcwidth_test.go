@@ -14,6 +14,11 @@ func TestStringCells(t *testing.T) {
 		{"single wide", "常", 2},
 		{"multiple latin", "abcd", 4},
 		{"multiple wide", "常用漢字", 8},
+		{"emoji", "\u2705", 2},
+		{"emoji supplementary", "\U0001f389", 2},
+		{"emoji presentation selector", "\u2764\ufe0f", 2},
+		{"text presentation", "\u2764", 1},
+		{"zwj sequence", "\U0001f468\u200d\U0001f4bb", 2},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1,7 +1,6 @@
 package markout
 
 import (
-	"bytes"
 	"io"
 )
 
@@ -23,6 +22,16 @@ type SectionWriter = interface {
 	Sectionf(format string, args ...any)
 	AttrSection(aa Attrs, a any)
 	AttrSectionf(aa Attrs, format string, args ...any)
+
+	// StartAppendices switches section numbering to appendix styles, the
+	// numbering restarts at the current section level.
+	StartAppendices()
+
+	// TableOfContents writes a placeholder that is replaced with the list of
+	// section headings when the writer is closed. Headings with levels
+	// exceeding max_level are not listed, use 0 to list all the headings.
+	// Output that follows the placeholder is buffered until Close().
+	TableOfContents(max_level int)
 }
 
 // TableRowWriter is a callback for writing table rows.
@@ -41,6 +50,14 @@ type TableWriter = interface {
 	//   - only TableRow() calls are supported in table mode.
 	//   - use EndTable() to exit from the table mode.
 	BeginTable(first_column any, other_columns ...any)
+
+	// BeginTableEx is a version of BeginTable() that supports per-column
+	// alignment, captions, and attributes.
+	BeginTableEx(spec TableSpec)
+
+	// TableRow writes a row of cells. In addition to inline content, cells
+	// can be built from multiple blocks with func(ParagraphWriter) or
+	// func(ListWriter) callbacks.
 	TableRow(first_cell any, other_cells ...any)
 	EndTable()
 
@@ -49,6 +66,47 @@ type TableWriter = interface {
 	Table(columns []any, rows func(callback TableRowWriter))
 }
 
+// Align specifies horizontal alignment of table columns.
+type Align int
+
+const (
+	AlignDefault = Align(iota)
+	AlignLeft
+	AlignCenter
+	AlignRight
+)
+
+// Overflow specifies how the content that does not fit into fixed-width table
+// columns is handled.
+type Overflow int
+
+const (
+	OverflowTruncate = Overflow(iota) // cut the content and append an ellipsis
+	OverflowWrap                      // wrap the content into multiple lines
+)
+
+// TableColumn describes a single table column.
+type TableColumn struct {
+	Header any
+	Align  Align
+	Width  int // fixed column width in character cells, 0 to measure content
+}
+
+// TableSpec describes the table layout for BeginTableEx().
+type TableSpec struct {
+	Columns []TableColumn
+	Caption any   // optional, nil for no caption
+	Attrs   Attrs // optional id, classes, and attributes (HTML only)
+
+	// Streaming tables write rows as they arrive instead of buffering the
+	// whole table until EndTable(). Column widths that are not fixed with
+	// TableColumn.Width are estimated from the first SampleRows rows.
+	// HTML tables are always streamed.
+	Streaming  bool
+	SampleRows int
+	Overflow   Overflow // handling of the content that exceeds column widths (TXT only)
+}
+
 type ListFlags uint
 
 const (
@@ -58,6 +116,24 @@ const (
 	Broad     = ListFlags(2)
 )
 
+// ListSpec describes an ordered or unordered list for BeginListEx().
+type ListSpec struct {
+	Flags ListFlags
+
+	// Start is the number of the first item in ordered lists, 0 defaults to
+	// 1. Ignored when Continue is set.
+	Start int
+
+	// Style specifies the numbering of ordered list items. Markdown does not
+	// support numbering styles, items are numbered with decimals.
+	Style NumberStyle
+
+	// Continue resumes numbering after the last item of the preceeding
+	// ordered list at the same nesting level, this allows lists to be
+	// interrupted with paragraphs or other blocks.
+	Continue bool
+}
+
 // ListWriter is an interface for writing items and child lists into list
 // blocks.
 type ListWriter interface {
@@ -71,6 +147,10 @@ type ListWriter interface {
 	BeginList(ListFlags)
 	EndList()
 
+	// BeginListEx is a version of BeginList() that supports custom start
+	// numbers, numbering styles, and continuation of ordered lists.
+	BeginListEx(spec ListSpec)
+
 	// ListItem writes an item into the list block. Must be called within the
 	// BeginList()/EndList() fragment.
 	ListItem(a any)
@@ -78,6 +158,10 @@ type ListWriter interface {
 	// ListItemf is a version of ListItem() with built-in formatting.
 	ListItemf(format string, args ...any)
 
+	// TaskItem writes a list item with a checkbox, checked or unchecked.
+	TaskItem(checked bool, a any)
+	TaskItemf(checked bool, format string, args ...any)
+
 	// Callback-based list writing methods that automatically wrap items
 	// BeginList/EndList blocks.
 	List(ListFlags, func(ListWriter))
@@ -96,9 +180,39 @@ type Writer interface {
 	TableWriter
 	CodeblockWriter
 
+	// RawBlock writes the content as-is into the targets of the specified
+	// format (FormatHTML, FormatMD, or FormatTXT), other targets skip it.
+	RawBlock(format string, content string)
+
+	// MathBlock writes a display formula in TeX notation.
+	MathBlock(tex string)
+
+	// Diagram writes a diagram block. The kind identifies the diagram
+	// language, such as MermaidDiagram, source is written as-is unless
+	// it is rendered with HTMLOptions.DiagramRenderer.
+	Diagram(kind, source string)
+
+	// ThematicBreak writes a horizontal rule that separates blocks of
+	// content.
+	ThematicBreak()
+
+	// PageBreak forces the content that follows to start on a new page when
+	// the document is printed.
+	PageBreak()
+
+	// DefineTerm registers an abbreviation or a glossary term that can be
+	// written with Term(). Terms must be defined before they are used.
+	DefineTerm(term, definition string)
+
 	Close()
 	CloseEx(ps func(ParagraphWriter))
 
+	// Err returns the errors detected while writing the document, such as
+	// the cross-references that could not be resolved or the terms that
+	// were not defined. Should be called
+	// after Close().
+	Err() error
+
 	DisableOutput()
 	EnableOutput()
 }
@@ -121,64 +235,113 @@ type TXTOptions struct {
 	QuotationMarks     string // pipe-separated single and double quotes (defaults to '|'|"|")
 	ListItemPrefix     string // content inserted before each list item (defaults to `* `)
 	UnderlinedSections bool
-	NumberedSections   bool
+	NumberedSections   bool              // shortcut for `1.2.` numbering when SectionNumbering is nil
+	SectionNumbering   *SectionNumbering // optional, nil for unnumbered sections
 	URLFilter          url_filter
+	Metadata           Metadata            // written as a `Key: value` header block
+	RuleWidth          int                 // width of thematic breaks in character cells (defaults to 72)
+	RulePattern        string              // content repeated to fill thematic breaks (defaults to `-`)
+	StyleMarkers       map[Style][2]string // overrides opening and closing markers of styled spans
+	CombiningStyles    bool                // strikethrough and underline with Unicode combining characters
+	ANSIColors         bool                // colored spans and badges with terminal escape sequences
+	Emoji              Emoji               // handling of emoji shortcodes, such as `:tada:`
+	SmartTypography    bool                // typographic quotes, dashes, and ellipses in plain strings
+	Glossary           string              // title of the section listing the used terms, written on Close
 }
 
 // NewTxt creates a new markout writer targeting plain text output.
 func NewTXT(out io.Writer, opts TXTOptions) Writer {
 	ii := &txt_inlines{}
 	ii.setup_quotation_marks(opts.QuotationMarks)
+	ii.setup_style_markers(opts.StyleMarkers)
+	ii.combining = opts.CombiningStyles
+	ii.ansi_colors = opts.ANSIColors
+	ii.emoji_mode = opts.Emoji
+	ii.smart = opts.SmartTypography
 	bb := &txt_blocks{}
 	bb.out = out
 	bb.listitem_prefix = opts.ListItemPrefix
 	bb.underlined_sections = opts.UnderlinedSections
-	bb.numbered_sections = opts.NumberedSections
+	bb.rule_width = opts.RuleWidth
+	bb.rule_pattern = opts.RulePattern
 	if bb.listitem_prefix == "" {
 		bb.listitem_prefix = "* "
 	}
+	if bb.rule_width <= 0 {
+		bb.rule_width = 72
+	}
+	if bb.rule_pattern == "" {
+		bb.rule_pattern = "-"
+	}
 	if opts.PutBOM {
 		bb.out.Write(RawContent("uFEFF"))
 	}
+	if len(opts.Metadata) > 0 {
+		bb.putblock(opts.Metadata.text_block())
+		bb.want_emptyln()
+	}
 
 	bb.sect_level_in()
-	return &writer_impl{
-		bb: bb,
-		p:  printer_impl{ii: ii, buf: &bytes.Buffer{}, url_filter: opts.URLFilter},
+	r := new_writer_impl(bb, ii, opts.URLFilter)
+	r.numbering = opts.SectionNumbering
+	r.glossary = opts.Glossary
+	if r.numbering == nil && opts.NumberedSections {
+		r.numbering = &SectionNumbering{Suffix: "."}
 	}
+	return r
 }
 
 type HTMLOptions struct {
-	PutBOM         bool
-	QuotationMarks string
-	Title          string
-	Style          string
-	ListTitleClass string
-	URLFilter      url_filter
+	PutBOM           bool
+	QuotationMarks   string
+	Title            string
+	Style            string
+	ListTitleClass   string
+	SectionNumbering *SectionNumbering // optional, nil for unnumbered sections
+	URLFilter        url_filter
+	Metadata         Metadata        // written as <meta> elements, "title" is used when Title is empty
+	MathRenderer     MathRenderer    // optional, formulas are written with TeX delimiters when nil
+	DiagramRenderer  DiagramRenderer // optional, diagrams are written as sources when nil
+	Scripts          []string        // URLs of the scripts loaded in the head, e.g. the Mermaid library
+	ExternalLinks    LinkAttrs       // default rel and target of the links to other sites, e.g. "noopener"
+	Autolink         bool            // link URLs and e-mail addresses found in plain strings
+	Emoji            Emoji           // handling of emoji shortcodes, such as `:tada:`
+	SmartTypography  bool            // typographic quotes, dashes, and ellipses in plain strings
+	Glossary         string          // title of the section listing the used terms, written on Close
 }
 
 // NewHtml creates a new markout writer targeting html output.
 func NewHTML(out io.Writer, opts HTMLOptions) Writer {
 	ii := &html_inlines{}
 	ii.setup_quotation_marks(opts.QuotationMarks)
+	ii.math_renderer = opts.MathRenderer
+	ii.emoji_mode = opts.Emoji
+	ii.smart = opts.SmartTypography
+	ii.external_links = LinkAttrs{Rel: opts.ExternalLinks.Rel, Target: opts.ExternalLinks.Target}
 	bb := &html_blocks{}
 	bb.out = out
+	bb.math_renderer = opts.MathRenderer
+	bb.diagram_renderer = opts.DiagramRenderer
 	bb.list_title_class = opts.ListTitleClass
 
-	r := &writer_impl{
-		bb: bb,
-		p:  printer_impl{ii: ii, buf: &bytes.Buffer{}, url_filter: opts.URLFilter},
-		on_close: func() {
-			bb.end_body()
-			bb.end_html()
-		},
+	r := new_writer_impl(bb, ii, opts.URLFilter)
+	r.numbering = opts.SectionNumbering
+	r.glossary = opts.Glossary
+	r.on_close = func() {
+		bb.end_body()
+		bb.end_html()
 	}
 
 	if opts.PutBOM {
 		bb.out.Write(RawContent("uFEFF"))
 	}
 	bb.begin_html()
-	bb.head(r.do_print(opts.Title), RawContent(opts.Style))
+	var title any = opts.Title
+	if opts.Title == "" && opts.Metadata["title"] != nil {
+		title = meta_text(opts.Metadata["title"])
+	}
+	bb.head(r.do_print(title), RawContent(opts.Style), opts.Metadata, opts.Scripts)
+	ii.autolink = opts.Autolink // not in the title
 	bb.begin_body()
 	bb.sect_level_in()
 
@@ -186,25 +349,62 @@ func NewHTML(out io.Writer, opts HTMLOptions) Writer {
 }
 
 type MDOptions struct {
-	PutBOM         bool
-	QuotationMarks string // pipe-separated single and double quotes (defaults to '|'|"|")
-	URLFilter      url_filter
-	HTMLLinks      bool
+	PutBOM           bool
+	QuotationMarks   string // pipe-separated single and double quotes (defaults to '|'|"|")
+	URLFilter        url_filter
+	HTMLLinks        bool
+	Emphasis         MDEmphasis        // syntax of emphasized and strong spans (defaults to HTML tags)
+	BracketedSpans   bool              // write AttrSpan attributes as pandoc bracketed spans
+	Badges           MDBadges          // badges as plain text or shields.io images
+	LinkStyle        MDLinkStyle       // inline or reference-style links
+	Autolink         bool              // link URLs and e-mail addresses found in plain strings
+	Emoji            Emoji             // handling of emoji shortcodes, such as `:tada:`
+	SmartTypography  bool              // typographic quotes, dashes, and ellipses in plain strings
+	Abbreviations    bool              // define the used terms with `*[TERM]: definition` at the end
+	Glossary         string            // title of the section listing the used terms, written on Close
+	HTMLSpans        bool              // write tables with spanned cells in HTML (repeats spanned content otherwise)
+	SectionNumbering *SectionNumbering // optional, nil for unnumbered sections
+	Metadata         Metadata
+	FrontMatter      FrontMatter // format of the metadata block (defaults to YAML)
 }
 
 // NewMD creates a new markout writer targeting markdown output.
 func NewMD(out io.Writer, opts MDOptions) Writer {
 	ii := &md_inlines{}
 	ii.html_links = opts.HTMLLinks
+	ii.autolink = opts.Autolink
+	ii.emoji_mode = opts.Emoji
+	ii.smart = opts.SmartTypography
+	ii.emphasis = opts.Emphasis
+	ii.bracketed_spans = opts.BracketedSpans
+	ii.badges = opts.Badges
+	ii.abbreviations = opts.Abbreviations
 	ii.setup_quotation_marks(opts.QuotationMarks)
 	bb := &md_blocks{}
 	bb.out = out
+	bb.html_spans = opts.HTMLSpans
+	if opts.LinkStyle != MDLinksInline && !opts.HTMLLinks {
+		bb.link_refs = &md_link_refs{style: opts.LinkStyle}
+		ii.link_refs = bb.link_refs
+	}
 	if opts.PutBOM {
 		bb.out.Write(RawContent("uFEFF"))
 	}
+	if len(opts.Metadata) > 0 {
+		bb.putblock(opts.Metadata.front_matter(opts.FrontMatter))
+		bb.want_emptyln()
+	}
 	bb.sect_level_in()
-	return &writer_impl{
-		bb: bb,
-		p:  printer_impl{ii: ii, buf: &bytes.Buffer{}, url_filter: opts.URLFilter},
+	r := new_writer_impl(bb, ii, opts.URLFilter)
+	r.numbering = opts.SectionNumbering
+	r.glossary = opts.Glossary
+	r.on_close = func() {
+		if bb.link_refs != nil {
+			bb.link_definitions()
+		}
+		if opts.Abbreviations {
+			bb.abbreviations(r.doc.terms.glossary())
+		}
 	}
+	return r
 }
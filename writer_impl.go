@@ -2,7 +2,6 @@ package markout
 
 import (
 	"bytes"
-	"fmt"
 	"strings"
 
 	"golang.org/x/exp/slices"
@@ -10,9 +9,24 @@ import (
 
 // writer_impl implements structured writing to the supported markout backends.
 type writer_impl struct {
-	on_close func()
-	bb       blocks // block-level formatting
-	p        printer_impl
+	on_close  func()
+	bb        blocks // block-level formatting
+	p         printer_impl
+	doc       *document
+	list_ends []int // last counters of ordered lists closed at each level
+	numbering *SectionNumbering
+	appendix  int    // section level where appendices start, 0 if none
+	glossary  string // title of the glossary section written on close
+}
+
+func new_writer_impl(bb blocks, ii inlines, uf url_filter) *writer_impl {
+	doc := &document{defer_output: bb.defer_output}
+	ii.set_document(doc)
+	return &writer_impl{
+		bb:  bb,
+		p:   printer_impl{ii: ii, buf: &bytes.Buffer{}, url_filter: uf},
+		doc: doc,
+	}
 }
 
 // Close finalizes writer output.
@@ -28,6 +42,10 @@ func (w *writer_impl) CloseEx(postscriptum func(ParagraphWriter)) {
 		w.bb.list_level_out()
 	}
 
+	if w.glossary != "" && len(w.doc.terms.glossary()) > 0 {
+		w.write_glossary()
+	}
+
 	if len(w.bb.sect_counters()) > 0 {
 		w.bb.sect_level_out()
 	}
@@ -44,14 +62,36 @@ func (w *writer_impl) CloseEx(postscriptum func(ParagraphWriter)) {
 	w.bb.close()
 }
 
+// write_glossary writes a top level section that lists the used terms.
+func (w *writer_impl) write_glossary() {
+	for len(w.bb.sect_counters()) > 1 {
+		w.bb.sect_level_out()
+	}
+	w.handle_section(w.glossary, nil)
+	w.BeginList(0)
+	for _, e := range w.doc.terms.glossary() {
+		e := e
+		w.ListItem(func(p Printer) {
+			p.Styled(StrongStyle, e.term)
+			p.WriteString(": " + e.definition)
+		})
+	}
+	w.EndList()
+}
+
 func (w *writer_impl) Close() {
 	w.CloseEx(nil)
 }
 
+func (w *writer_impl) Err() error {
+	return w.doc.err()
+}
+
 func (w *writer_impl) do_print(a any) RawContent {
 	w.p.buf.Reset()
 	if w.bb.enabled() {
 		w.p.Print(a)
+		w.p.ii.end_content(w.p.buf, true)
 	}
 	return w.p.buf.Bytes()
 }
@@ -60,25 +100,60 @@ func (w *writer_impl) do_printf(format string, args ...any) RawContent {
 	w.p.buf.Reset()
 	if w.bb.enabled() {
 		w.p.Printf(format, args...)
+		w.p.ii.end_content(w.p.buf, true)
 	}
 	return w.p.buf.Bytes()
 }
 
-func (w *writer_impl) handle_section(s RawContent, aa *Attrs) {
+// print_unlinked formats the content with the links replaced by their
+// captions.
+func (w *writer_impl) print_unlinked(a any) RawContent {
+	b := bytes.Buffer{}
+	ii := unlinked_inlines{w.p.ii}
+	to_buffer(&b, ii, w.p.url_filter, a)
+	ii.end_content(&b, true)
+	return b.Bytes()
+}
+
+func (w *writer_impl) handle_section(a any, aa *Attrs) {
 	w.bb.check_mode(mflow)
 	cc := w.bb.sect_counters()
 	cc[len(cc)-1]++
-	w.bb.heading(cc, s, aa)
+	h := &heading_info{
+		counters: slices.Clone(cc),
+		caption:  slices.Clone(w.do_print(a)),
+		attrs:    aa,
+		appendix: w.appendix > 0 && len(cc) >= w.appendix,
+		// markdown renderers generate identifiers from the numbered caption
+		slug_num: w.p.ii.format() == FormatMD,
+	}
+	if w.numbering != nil {
+		h.number = w.numbering.number(cc, w.appendix)
+		h.suffix = w.numbering.Suffix
+	}
+	if w.bb.enabled() {
+		h.text = plain_text(a)
+		h.linkable = w.print_unlinked(a)
+		w.doc.headings.add(h)
+	}
+	w.bb.heading(h)
 }
 
-func (w *writer_impl) handle_listitem(s ...RawContent) {
+// printf_callback wraps formatted output into a Callback.
+func printf_callback(format string, args ...any) Callback {
+	return func(p Printer) {
+		p.Printf(format, args...)
+	}
+}
+
+func (w *writer_impl) handle_listitem(task list_task, s ...RawContent) {
 	w.bb.check_mode(mlist)
 	cc, broads := w.bb.list_level_info()
 	n := len(cc) - 1
 	if cc[n] >= 0 {
 		cc[n]++
 	}
-	w.bb.list_item(cc, broads[n], s...)
+	w.bb.list_item(cc, broads[n], task, s...)
 }
 
 func (w *writer_impl) Para(a any) {
@@ -95,28 +170,28 @@ func (w *writer_impl) Paraf(format string, args ...any) {
 
 func (w *writer_impl) BeginSection(a any) {
 	if w.bb.check_mode(mflow) {
-		w.handle_section(w.do_print(a), nil)
+		w.handle_section(a, nil)
 		w.bb.sect_level_in()
 	}
 }
 
 func (w *writer_impl) BeginSectionf(format string, args ...any) {
 	if w.bb.check_mode(mflow) {
-		w.handle_section(w.do_printf(format, args...), nil)
+		w.handle_section(printf_callback(format, args...), nil)
 		w.bb.sect_level_in()
 	}
 }
 
 func (w *writer_impl) BeginAttrSection(aa Attrs, a any) {
 	if w.bb.check_mode(mflow) {
-		w.handle_section(w.do_print(a), &aa)
+		w.handle_section(a, &aa)
 		w.bb.sect_level_in()
 	}
 }
 
 func (w *writer_impl) BeginAttrSectionf(aa Attrs, format string, args ...any) {
 	if w.bb.check_mode(mflow) {
-		w.handle_section(w.do_printf(format, args...), &aa)
+		w.handle_section(printf_callback(format, args...), &aa)
 		w.bb.sect_level_in()
 	}
 }
@@ -129,50 +204,127 @@ func (w *writer_impl) EndSection() {
 
 func (w *writer_impl) Section(a any) {
 	if w.bb.check_mode(mflow) {
-		w.handle_section(w.do_print(a), nil)
+		w.handle_section(a, nil)
 	}
 }
 
 func (w *writer_impl) Sectionf(format string, args ...any) {
 	if w.bb.check_mode(mflow) {
-		w.handle_section(w.do_printf(format, args...), nil)
+		w.handle_section(printf_callback(format, args...), nil)
 	}
 }
 
 func (w *writer_impl) AttrSection(aa Attrs, a any) {
 	if w.bb.check_mode(mflow) {
-		w.handle_section(w.do_print(a), &aa)
+		w.handle_section(a, &aa)
 	}
 }
 
 func (w *writer_impl) AttrSectionf(aa Attrs, format string, args ...any) {
 	if w.bb.check_mode(mflow) {
-		w.handle_section(w.do_printf(format, args...), &aa)
+		w.handle_section(printf_callback(format, args...), &aa)
+	}
+}
+
+func (w *writer_impl) StartAppendices() {
+	if w.bb.check_mode(mflow) {
+		cc := w.bb.sect_counters()
+		cc[len(cc)-1] = 0
+		w.appendix = len(cc)
+	}
+}
+
+func (w *writer_impl) TableOfContents(max_level int) {
+	if w.bb.check_mode(mflow) && w.bb.enabled() {
+		w.bb.placeholder_block(func() RawContent {
+			return w.bb.toc(w.doc.headings.entries, max_level)
+		})
 	}
 }
 
 func (w *writer_impl) BeginTable(first_column any, other_columns ...any) {
+	spec := TableSpec{Columns: make([]TableColumn, 0, 1+len(other_columns))}
+	spec.Columns = append(spec.Columns, TableColumn{Header: first_column})
+	for _, c := range other_columns {
+		spec.Columns = append(spec.Columns, TableColumn{Header: c})
+	}
+	w.BeginTableEx(spec)
+}
+
+func (w *writer_impl) BeginTableEx(spec TableSpec) {
 	if w.bb.check_mode(mflow) {
-		rr := make([]RawContent, 0, 1+len(other_columns))
-		rr = append(rr, slices.Clone(w.do_print(first_column)))
-		for _, c := range other_columns {
-			rr = append(rr, slices.Clone(w.do_print(c)))
+		opts := table_opts{
+			aligns:      make([]Align, 0, len(spec.Columns)),
+			widths:      make([]int, 0, len(spec.Columns)),
+			streaming:   spec.Streaming,
+			sample_rows: spec.SampleRows,
+			overflow:    spec.Overflow,
+		}
+		rr := make([]table_cell, 0, len(spec.Columns))
+		for _, c := range spec.Columns {
+			rr = append(rr, w.make_cell(c.Header))
+			opts.aligns = append(opts.aligns, c.Align)
+			opts.widths = append(opts.widths, c.Width)
+		}
+		if spec.Caption != nil {
+			opts.caption = slices.Clone(w.do_print(spec.Caption))
 		}
-		w.bb.begin_table(rr...)
+		if spec.Attrs.Identifier != "" || len(spec.Attrs.Classes) > 0 || len(spec.Attrs.KeyVals) > 0 {
+			opts.attrs = &spec.Attrs
+		}
+		w.bb.begin_table(opts, rr...)
 	}
 }
 
 func (w *writer_impl) TableRow(first_cell any, other_cells ...any) {
 	if w.bb.check_mode(mtable) && w.bb.enabled() {
-		rr := make([]RawContent, 0, 1+len(other_cells))
-		rr = append(rr, slices.Clone(w.do_print(first_cell)))
+		rr := make([]table_cell, 0, 1+len(other_cells))
+		rr = append(rr, w.make_cell(first_cell))
 		for _, c := range other_cells {
-			rr = append(rr, slices.Clone(w.do_print(c)))
+			rr = append(rr, w.make_cell(c))
 		}
 		w.bb.table_row(rr...)
 	}
 }
 
+// make_cell prints table cell content, unwrapping the cells that span
+// multiple columns and/or rows. Cells that are built with func(ParagraphWriter)
+// or func(ListWriter) callbacks may contain multiple blocks.
+func (w *writer_impl) make_cell(a any) table_cell {
+	c := table_cell{cols: 1, rows: 1}
+	if v, ok := a.(cell_span); ok {
+		a = v.content
+		if v.cols > 1 {
+			c.cols = v.cols
+		}
+		if v.rows > 1 {
+			c.rows = v.rows
+		}
+	}
+	switch v := a.(type) {
+	case func(ParagraphWriter):
+		blocks := multi_block_sink{ii: w.p.ii, url_filter: w.p.url_filter}
+		if w.bb.enabled() {
+			v(&blocks)
+		}
+		for _, b := range blocks.blocks {
+			c.blocks = append(c.blocks, cell_block{content: b})
+		}
+	case func(ListWriter):
+		items := cell_list_sink{ii: w.p.ii, url_filter: w.p.url_filter}
+		if w.bb.enabled() {
+			v(&items)
+		}
+		c.blocks = items.blocks
+	default:
+		c.blocks = []cell_block{{content: slices.Clone(w.do_print(a))}}
+	}
+	if len(c.blocks) == 0 {
+		c.blocks = []cell_block{{}}
+	}
+	return c
+}
+
 func (w *writer_impl) EndTable() {
 	if w.bb.check_mode(mtable) {
 		w.bb.end_table()
@@ -192,17 +344,47 @@ func (w *writer_impl) ListTitlef(format string, args ...any) {
 }
 
 func (w *writer_impl) BeginList(f ListFlags) {
+	w.BeginListEx(ListSpec{Flags: f})
+}
+
+func (w *writer_impl) BeginListEx(spec ListSpec) {
 	if w.bb.check_mode(mflow | mlist) {
-		init := -1
-		if f&Ordered != 0 {
-			init = 0
-		}
-		w.bb.list_level_in(init, f&Broad != 0)
+		cc, _ := w.bb.list_level_info()
+		w.bb.list_level_in(list_initial(spec, w.list_ends, len(cc)), spec.Flags&Broad != 0, spec.Style)
 		cc, broads := w.bb.list_level_info()
 		w.bb.list_level_start(cc, len(broads) >= 2 && broads[len(broads)-2])
 	}
 }
 
+// list_initial returns the initial counter for a list that begins at the
+// specified nesting depth: -1 for unordered lists, the number preceeding the
+// first item for ordered lists.
+func list_initial(spec ListSpec, ends []int, depth int) int {
+	switch {
+	case spec.Flags&Ordered == 0:
+		return -1
+	case spec.Continue && depth < len(ends):
+		return ends[depth]
+	case spec.Start > 0:
+		return spec.Start - 1
+	default:
+		return 0
+	}
+}
+
+// list_ended records the last counter of an ordered list that ends at the
+// specified nesting depth.
+func list_ended(ends []int, depth int, counter int) []int {
+	if counter < 0 {
+		return ends
+	}
+	for len(ends) <= depth {
+		ends = append(ends, 0)
+	}
+	ends[depth] = counter
+	return ends
+}
+
 type multi_block_sink struct {
 	ii         inlines
 	url_filter url_filter
@@ -210,22 +392,117 @@ type multi_block_sink struct {
 }
 
 func (b *multi_block_sink) Para(a any) {
-	buf := &bytes.Buffer{}
-	to_buffer(buf, b.ii, b.url_filter, a)
+	buf := block_buffer(b.ii, b.url_filter, a)
 	b.blocks = append(b.blocks, buf.Bytes())
 }
 
 func (b *multi_block_sink) Paraf(format string, args ...any) {
+	b.Para(printf_callback(format, args...))
+}
+
+// block_buffer writes the content of a complete block into a new buffer.
+func block_buffer(ii inlines, uf url_filter, a any) *bytes.Buffer {
 	buf := &bytes.Buffer{}
-	scratch := bytes.Buffer{}
-	b.ii.put_str(&scratch, format)
-	fmt_raw := scratch.String()
-	args_raw := fmt_args(&scratch, b.ii, b.url_filter, args...)
-	fmt.Fprintf(buf, fmt_raw, args_raw...)
-	b.blocks = append(b.blocks, buf.Bytes())
+	to_buffer(buf, ii, uf, a)
+	ii.end_content(buf, true)
+	return buf
+}
+
+// cell_list_sink collects list items written into table cells. Items written
+// outside of BeginList/EndList form an unordered list.
+type cell_list_sink struct {
+	ii         inlines
+	url_filter url_filter
+	counters   []int
+	styles     []NumberStyle
+	ends       []int
+	blocks     []cell_block
+}
+
+func (b *cell_list_sink) ListTitle(a any) {
+	buf := block_buffer(b.ii, b.url_filter, a)
+	b.blocks = append(b.blocks, cell_block{content: buf.Bytes()})
+}
+
+func (b *cell_list_sink) ListTitlef(format string, args ...any) {
+	b.ListTitle(printf_callback(format, args...))
+}
+
+func (b *cell_list_sink) BeginList(f ListFlags) {
+	b.BeginListEx(ListSpec{Flags: f})
+}
+
+func (b *cell_list_sink) BeginListEx(spec ListSpec) {
+	b.counters = append(b.counters, list_initial(spec, b.ends, len(b.counters)))
+	b.styles = append(b.styles, spec.Style)
+}
+
+func (b *cell_list_sink) EndList() {
+	if n := len(b.counters); n > 0 {
+		b.ends = list_ended(b.ends, n-1, b.counters[n-1])
+		b.counters = b.counters[:n-1]
+		b.styles = b.styles[:n-1]
+	}
+}
+
+func (b *cell_list_sink) ListItem(a any) {
+	b.list_item(task_none, a)
+}
+
+func (b *cell_list_sink) TaskItem(checked bool, a any) {
+	b.list_item(make_task(checked), a)
+}
+
+func (b *cell_list_sink) TaskItemf(checked bool, format string, args ...any) {
+	b.list_item(make_task(checked), printf_callback(format, args...))
+}
+
+func (b *cell_list_sink) list_item(task list_task, a any) {
+	if len(b.counters) == 0 {
+		b.BeginList(Unordered)
+	}
+	n := len(b.counters) - 1
+	if b.counters[n] >= 0 {
+		b.counters[n]++
+	}
+	buf := block_buffer(b.ii, b.url_filter, a)
+	b.blocks = append(b.blocks, cell_block{content: buf.Bytes(), level: n + 1, counter: b.counters[n], style: b.styles[n], task: task})
+}
+
+func (b *cell_list_sink) ListItemf(format string, args ...any) {
+	b.ListItem(printf_callback(format, args...))
+}
+
+func (b *cell_list_sink) List(f ListFlags, items func(ListWriter)) {
+	if items == nil {
+		return
+	}
+	b.BeginList(f)
+	defer b.EndList()
+	items(b)
 }
 
 func (w *writer_impl) ListItem(a any) {
+	w.list_item(task_none, a)
+}
+
+func (w *writer_impl) ListItemf(format string, args ...any) {
+	if w.bb.check_mode(mlist) {
+		w.handle_listitem(task_none, w.do_printf(format, args...))
+	}
+}
+
+func (w *writer_impl) TaskItem(checked bool, a any) {
+	w.list_item(make_task(checked), a)
+}
+
+func (w *writer_impl) TaskItemf(checked bool, format string, args ...any) {
+	if w.bb.check_mode(mlist) {
+		w.handle_listitem(make_task(checked), w.do_printf(format, args...))
+	}
+}
+
+func (w *writer_impl) list_item(task list_task, a any) {
 	if w.bb.check_mode(mlist) {
 		if ml, ok := a.(func(w ParagraphWriter)); ok {
 			blocks := multi_block_sink{
@@ -233,34 +510,29 @@ func (w *writer_impl) ListItem(a any) {
 				url_filter: w.p.url_filter,
 			}
 			ml(&blocks)
-			w.handle_listitem(blocks.blocks...)
+			w.handle_listitem(task, blocks.blocks...)
 
 		} else {
-			w.handle_listitem(w.do_print(a))
+			w.handle_listitem(task, w.do_print(a))
 		}
 	}
 }
 
-func (w *writer_impl) ListItemf(format string, args ...any) {
-	if w.bb.check_mode(mlist) {
-		w.handle_listitem(w.do_printf(format, args...))
-	}
-}
-
 func (w *writer_impl) EndList() {
 	if w.bb.check_mode(mlist) {
 		cc, broads := w.bb.list_level_info()
 		w.bb.list_level_done(cc, len(broads) >= 2 && broads[len(broads)-2])
+		w.list_ends = list_ended(w.list_ends, len(cc)-1, cc[len(cc)-1])
 		w.bb.list_level_out()
 	}
 }
 
 func (w *writer_impl) Table(columns []any, rows func(TableRowWriter)) {
 	if w.bb.check_mode(mflow) && w.bb.enabled() {
-		if rows == nil {
+		if len(columns) == 0 || rows == nil {
 			return
 		}
-		w.BeginTable(columns)
+		w.BeginTable(columns[0], columns[1:]...)
 		defer w.EndTable()
 
 		on_row := func(first_cell any, other_cells ...any) {
@@ -290,6 +562,44 @@ func (w *writer_impl) EnableOutput() {
 	w.bb.pop_disabled()
 }
 
+func (w *writer_impl) RawBlock(format string, content string) {
+	if w.bb.check_mode(mflow) && w.p.ii.format() == format {
+		w.bb.raw_block(RawContent(content))
+	}
+}
+
+func (w *writer_impl) MathBlock(tex string) {
+	if w.bb.check_mode(mflow) {
+		if err := w.bb.math_block(tex); err != nil && w.bb.enabled() {
+			w.doc.failures = append(w.doc.failures, math_error(tex, err))
+		}
+	}
+}
+
+func (w *writer_impl) Diagram(kind, source string) {
+	if w.bb.check_mode(mflow) {
+		if err := w.bb.diagram(kind, source); err != nil && w.bb.enabled() {
+			w.doc.failures = append(w.doc.failures, diagram_error(kind, err))
+		}
+	}
+}
+
+func (w *writer_impl) DefineTerm(term, definition string) {
+	w.doc.terms.define(term, definition)
+}
+
+func (w *writer_impl) ThematicBreak() {
+	if w.bb.check_mode(mflow) {
+		w.bb.thematic_break()
+	}
+}
+
+func (w *writer_impl) PageBreak() {
+	if w.bb.check_mode(mflow) {
+		w.bb.page_break()
+	}
+}
+
 func (w *writer_impl) Codeblock(lang string, lines string) {
 	w.bb.codeblock(lang, w.do_print(func(p Printer) {
 		for i, ln := range strings.Split(lines, "\n") {
@@ -5,8 +5,27 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
+// write_each writes the same content with a writer for each of the options,
+// which can be TXTOptions, MDOptions, or HTMLOptions.
+func write_each(write func(w Writer), opts ...any) {
+	for _, o := range opts {
+		var w Writer
+		switch o := o.(type) {
+		case TXTOptions:
+			w = NewTXT(os.Stdout, o)
+		case MDOptions:
+			w = NewMD(os.Stdout, o)
+		case HTMLOptions:
+			w = NewHTML(os.Stdout, o)
+		}
+		write(w)
+		w.Close()
+	}
+}
+
 func ExampleNewTXT() {
 	buf := bytes.Buffer{}
 	w := NewTXT(&buf, TXTOptions{
@@ -178,9 +197,9 @@ func ExampleNewMD() {
 	//
 	// Inline formatting: "<em>Hello</em>, <strong>World\!</strong>"
 	//
-	// |th | thead
-	// |---|------
-	// |tcell | tcell
+	// | th    | thead
+	// |-------|-------
+	// | tcell | tcell
 	//
 	// ```go
 	// codeblock
@@ -253,11 +272,11 @@ func ExampleNewHTML() {
 	// </tbody>
 	// </table>
 	//
-	// <h1>Section</h1>
+	// <h1 id="section">Section</h1>
 	//
 	// <h2 id="ident" class="cls">SubSection</h2>
 	//
-	// <h3>SubSubSection</h3>
+	// <h3 id="subsubsection">SubSubSection</h3>
 	//
 	// <pre lang="go">
 	// codeblock
@@ -275,6 +294,54 @@ func ExampleNewHTML() {
 	// </html>
 }
 
+func ExampleWriter_BeginTableEx() {
+	spec := TableSpec{
+		Columns: []TableColumn{
+			{Header: "Item"},
+			{Header: "Qty", Align: AlignCenter},
+			{Header: "Price", Align: AlignRight},
+		},
+		Caption: "Totals",
+		Attrs:   Attrs{Classes: []string{"report"}},
+	}
+	rows := [][]any{{"apples", 3, 1.25}, {"oranges", 12, 10.5}}
+
+	write_each(func(w Writer) {
+		w.BeginTableEx(spec)
+		for _, r := range rows {
+			w.TableRow(r[0], r[1:]...)
+		}
+		w.EndTable()
+	}, TXTOptions{}, MDOptions{}, HTMLOptions{})
+	// Output:
+	// Totals
+	// Item    Qty Price
+	// ------- --- -----
+	// apples   3   1.25
+	// oranges 12   10.5
+	//
+	// Totals
+	//
+	// | Item    | Qty | Price
+	// |---------|:---:|------:
+	// | apples  |  3  |  1.25
+	// | oranges | 12  |  10.5
+	//
+	// <html>
+	// <body>
+	// <table class="report">
+	// <caption>Totals</caption>
+	// <thead><tr><th>Item</th><th style="text-align:center">Qty</th><th style="text-align:right">Price</th></tr></thead>
+	// <tbody>
+	// <tr><td>apples</td><td style="text-align:center">3</td><td style="text-align:right">1.25</td></tr>
+	// <tr><td>oranges</td><td style="text-align:center">12</td><td style="text-align:right">10.5</td></tr>
+	// </tbody>
+	// </table>
+	//
+	// </body>
+	// </html>
+}
+
 func ExampleURL() {
 	url_filter := func(url string) []byte {
 		return []byte(filepath.Base(url))
@@ -289,3 +356,314 @@ func ExampleURL() {
 	//
 	// URL: path.txt
 }
+
+func ExampleWriter_TableRow_blocks() {
+	write_each(func(w Writer) {
+		w.BeginTable("Step", "Details")
+		w.TableRow(1, func(p ParagraphWriter) {
+			p.Para("Download the archive")
+			p.Para(RawContent("from mirror a\nor mirror b"))
+		})
+		w.TableRow(2, func(l ListWriter) {
+			l.ListItem("unpack")
+			l.List(Ordered, func(l ListWriter) {
+				l.ListItem("run a|b")
+				l.ListItem("verify")
+			})
+		})
+		w.EndTable()
+	}, TXTOptions{}, MDOptions{}, HTMLOptions{})
+	// Output:
+	// Step Details
+	// ---- --------------------
+	// 1    Download the archive
+	//
+	//      from mirror a
+	//      or mirror b
+	// 2    * unpack
+	//        1. run a|b
+	//        2. verify
+	//
+	// | Step | Details
+	// |------|--------------------------------------------------------------
+	// | 1    | Download the archive<br><br>from mirror a<br>or mirror b
+	// | 2    | - unpack<br>&nbsp;&nbsp;1. run a\|b<br>&nbsp;&nbsp;2. verify
+	//
+	// <html>
+	// <body>
+	// <table>
+	// <thead><tr><th>Step</th><th>Details</th></tr></thead>
+	// <tbody>
+	// <tr><td>1</td><td><p>Download the archive</p><p>from mirror a
+	// or mirror b</p></td></tr>
+	// <tr><td>2</td><td><ul><li>unpack<ol><li>run a|b</li><li>verify</li></ol></li></ul></td></tr>
+	// </tbody>
+	// </table>
+	//
+	// </body>
+	// </html>
+}
+
+func ExampleTableSpec_streaming() {
+	buf := bytes.Buffer{}
+	w := NewTXT(&buf, TXTOptions{})
+	w.BeginTableEx(TableSpec{
+		Columns: []TableColumn{
+			{Header: "ID", Width: 3, Align: AlignRight},
+			{Header: "Description", Width: 12},
+		},
+		Streaming: true,
+	})
+	w.TableRow(1, "short")
+	fmt.Printf("before EndTable:\n%s\n", buf.String())
+	w.TableRow(2, "a rather long description")
+	w.EndTable()
+	w.BeginTableEx(TableSpec{
+		Columns: []TableColumn{
+			{Header: "ID", Align: AlignRight},
+			{Header: "Description", Width: 12},
+		},
+		Streaming:  true,
+		SampleRows: 1,
+		Overflow:   OverflowWrap,
+	})
+	w.TableRow(1, "short")
+	w.TableRow(7, "a rather long description")
+	w.Close()
+	fmt.Print(buf.String())
+	// Output:
+	// before EndTable:
+	//  ID Description
+	// --- ------------
+	//   1 short
+	//  ID Description
+	// --- ------------
+	//   1 short
+	//   2 a rather lo…
+	//
+	// ID Description
+	// -- ------------
+	//  1 short
+	//  7 a rather
+	//    long
+	//    description
+}
+
+func ExampleWriter_TableOfContents() {
+	write_each(func(w Writer) {
+		w.TableOfContents(2)
+		w.BeginSection("Introduction")
+		w.Section("Scope")
+		w.Section(Code("go build"))
+		w.BeginSection("Details")
+		w.Section("Too deep")
+		w.EndSection()
+		w.EndSection()
+		w.Section("Introduction")
+	}, TXTOptions{NumberedSections: true}, MDOptions{}, HTMLOptions{})
+	// Output:
+	// 1. Introduction
+	//   1.1. Scope
+	//   1.2. `go build`
+	//   1.3. Details
+	// 2. Introduction
+	//
+	// 1. Introduction
+	//
+	// 1.1. Scope
+	//
+	// 1.2. `go build`
+	//
+	// 1.3. Details
+	//
+	// 1.3.1. Too deep
+	//
+	// 2. Introduction
+	//
+	// - [Introduction](#introduction)
+	//   - [Scope](#scope)
+	//   - [`go build`](#go-build)
+	//   - [Details](#details)
+	// - [Introduction](#introduction-1)
+	//
+	// # Introduction
+	//
+	// ## Scope
+	//
+	// ## `go build`
+	//
+	// ## Details
+	//
+	// ### Too deep
+	//
+	// # Introduction
+	//
+	// <html>
+	// <body>
+	// <nav class="toc">
+	// <ul>
+	// <li><a href="#introduction">Introduction</a>
+	// <ul>
+	// <li><a href="#scope">Scope</a></li>
+	// <li><a href="#go-build"><code>go build</code></a></li>
+	// <li><a href="#details">Details</a></li>
+	// </ul></li>
+	// <li><a href="#introduction-1">Introduction</a></li>
+	// </ul>
+	// </nav>
+	//
+	// <h1 id="introduction">Introduction</h1>
+	//
+	// <h2 id="scope">Scope</h2>
+	//
+	// <h2 id="go-build"><code>go build</code></h2>
+	//
+	// <h2 id="details">Details</h2>
+	//
+	// <h3 id="too-deep">Too deep</h3>
+	//
+	// <h1 id="introduction-1">Introduction</h1>
+	//
+	// </body>
+	// </html>
+}
+
+func ExampleWriter_ThematicBreak() {
+	write := func(w Writer) {
+		w.Para("Before")
+		w.ThematicBreak()
+		w.Para("After")
+		w.PageBreak()
+		w.Para("Next page")
+	}
+	buf := bytes.Buffer{}
+	w := NewTXT(&buf, TXTOptions{RuleWidth: 9, RulePattern: "=-"})
+	write(w)
+	w.Close()
+	fmt.Print(strings.ReplaceAll(buf.String(), "\f", "<FF>"))
+	write_each(write, MDOptions{}, HTMLOptions{})
+	// Output:
+	// Before
+	//
+	// =-=-=-=-=
+	//
+	// After
+	//
+	// <FF>
+	// Next page
+	//
+	// Before
+	//
+	// ---
+	//
+	// After
+	//
+	// <div style="page-break-after: always"></div>
+	//
+	// Next page
+	//
+	// <html>
+	// <body>
+	// <p>Before</p>
+	//
+	// <hr>
+	//
+	// <p>After</p>
+	//
+	// <div style="page-break-after: always"></div>
+	//
+	// <p>Next page</p>
+	//
+	// </body>
+	// </html>
+}
+
+func ExampleListWriter_TaskItem() {
+	write_each(func(w Writer) {
+		w.List(Unordered, func(lw ListWriter) {
+			lw.TaskItem(true, "Tag the release")
+			lw.TaskItemf(false, "Publish notes%son the blog", LineBreak)
+			lw.List(Ordered, func(lw ListWriter) {
+				lw.TaskItem(false, "Changelog")
+				lw.ListItem("Announcement")
+			})
+		})
+	}, TXTOptions{}, MDOptions{}, HTMLOptions{})
+	// Output:
+	// * [x] Tag the release
+	// * [ ] Publish notes
+	//       on the blog
+	//   1. [ ] Changelog
+	//   2. Announcement
+	//
+	// - [x] Tag the release
+	// - [ ] Publish notes\
+	//       on the blog
+	//   1. [ ] Changelog
+	//   2. Announcement
+	//
+	// <html>
+	// <body>
+	// <ul>
+	//   <li><input type="checkbox" checked disabled> Tag the release</li>
+	//   <li><input type="checkbox" disabled> Publish notes<br>on the blog</li>
+	//   <ol>
+	//     <li><input type="checkbox" disabled> Changelog</li>
+	//     <li>Announcement</li>
+	//   </ol>
+	// </ul>
+	//
+	// </body>
+	// </html>
+}
+
+func ExampleWriter_BeginListEx() {
+	write_each(func(w Writer) {
+		w.BeginListEx(ListSpec{Flags: Ordered, Start: 3, Style: NumberLowerRoman})
+		w.ListItem("Third")
+		w.ListItem("Fourth")
+		w.EndList()
+		w.Para("Interruption")
+		w.BeginListEx(ListSpec{Flags: Ordered, Style: NumberLowerRoman, Continue: true})
+		w.ListItem("Fifth")
+		w.BeginListEx(ListSpec{Flags: Ordered, Style: NumberUpperAlpha})
+		w.ListItem("Nested")
+		w.EndList()
+		w.EndList()
+	}, TXTOptions{}, MDOptions{}, HTMLOptions{})
+	// Output:
+	// iii. Third
+	// iv. Fourth
+	//
+	// Interruption
+	//
+	// v. Fifth
+	//   A. Nested
+	//
+	// 3. Third
+	// 4. Fourth
+	//
+	// Interruption
+	//
+	// 5. Fifth
+	//   1. Nested
+	//
+	// <html>
+	// <body>
+	// <ol start="3" type="i">
+	//   <li>Third</li>
+	//   <li>Fourth</li>
+	// </ol>
+	//
+	// <p>Interruption</p>
+	//
+	// <ol start="5" type="i">
+	//   <li>Fifth</li>
+	//   <ol type="A">
+	//     <li>Nested</li>
+	//   </ol>
+	// </ol>
+	//
+	// </body>
+	// </html>
+}